@@ -0,0 +1,85 @@
+package errbatch
+
+import (
+	"errors"
+)
+
+// Aggregation decides how the batch aggregates the predicates of its
+// underlying errors (see Timeout, Temporary and Retryable).
+type Aggregation int
+
+// Aggregation values.
+const (
+	// AggregateAll reports true only when all the underlying errors satisfy
+	// the predicate.
+	//
+	// It's the default, as it's the conservative choice for decisions like
+	// retrying.
+	AggregateAll Aggregation = iota
+
+	// AggregateAny reports true when any of the underlying errors satisfies
+	// the predicate.
+	AggregateAny
+)
+
+// WithAggregation sets how the batch aggregates the predicates of its
+// underlying errors (see Timeout, Temporary and Retryable).
+func WithAggregation(a Aggregation) Option {
+	return func(o *options) {
+		o.aggregation = a
+	}
+}
+
+// Timeout reports whether the batch is a timeout,
+// according to the Timeout() bool methods (e.g. net.Error) of its underlying
+// errors and WithAggregation.
+//
+// Errors without the method are treated as not satisfying the predicate.
+// An empty batch is never a timeout.
+func (eb *ErrBatch) Timeout() bool {
+	return eb.aggregate(func(err error) bool {
+		var e interface{ Timeout() bool }
+		return errors.As(err, &e) && e.Timeout()
+	})
+}
+
+// Temporary reports whether the batch is temporary,
+// according to the Temporary() bool methods of its underlying errors and
+// WithAggregation.
+//
+// Errors without the method are treated as not satisfying the predicate.
+// An empty batch is never temporary.
+func (eb *ErrBatch) Temporary() bool {
+	return eb.aggregate(func(err error) bool {
+		var e interface{ Temporary() bool }
+		return errors.As(err, &e) && e.Temporary()
+	})
+}
+
+// Retryable reports whether the batch is retryable,
+// according to the Retryable() bool methods of its underlying errors and
+// WithAggregation.
+//
+// Errors without the method are treated as not satisfying the predicate.
+// An empty batch is never retryable.
+func (eb *ErrBatch) Retryable() bool {
+	return eb.aggregate(func(err error) bool {
+		var e interface{ Retryable() bool }
+		return errors.As(err, &e) && e.Retryable()
+	})
+}
+
+func (eb *ErrBatch) aggregate(pred func(error) bool) bool {
+	if len(eb.entries) == 0 {
+		return false
+	}
+	// With AggregateAny, stop at the first error satisfying pred;
+	// with AggregateAll, stop at the first one not satisfying it.
+	stopAt := eb.getOptions().aggregation == AggregateAny
+	for _, e := range eb.entries {
+		if pred(e.err) == stopAt {
+			return stopAt
+		}
+	}
+	return !stopAt
+}
@@ -0,0 +1,97 @@
+package errbatch_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/fishy/errbatch"
+)
+
+type predicateError struct {
+	timeout, temporary, retryable bool
+}
+
+func (predicateError) Error() string {
+	return "predicate"
+}
+
+func (e predicateError) Timeout() bool {
+	return e.timeout
+}
+
+func (e predicateError) Temporary() bool {
+	return e.temporary
+}
+
+func (e predicateError) Retryable() bool {
+	return e.retryable
+}
+
+func TestAggregation(t *testing.T) {
+	type result struct {
+		timeout, temporary, retryable bool
+	}
+	check := func(t *testing.T, batch *errbatch.ErrBatch, expected result) {
+		t.Helper()
+		actual := result{
+			timeout:   batch.Timeout(),
+			temporary: batch.Temporary(),
+			retryable: batch.Retryable(),
+		}
+		if actual != expected {
+			t.Errorf("Expected %#v, got %#v", expected, actual)
+		}
+	}
+	errs := []error{
+		predicateError{timeout: true, temporary: true, retryable: true},
+		predicateError{timeout: true},
+		errors.New("foo"),
+	}
+
+	for _, c := range []struct {
+		label       string
+		aggregation errbatch.Aggregation
+		errs        []error
+		expected    result
+	}{
+		{
+			label:       "all-empty",
+			aggregation: errbatch.AggregateAll,
+		},
+		{
+			label:       "all",
+			aggregation: errbatch.AggregateAll,
+			errs:        errs[:2],
+			expected:    result{timeout: true},
+		},
+		{
+			label:       "all-unknown",
+			aggregation: errbatch.AggregateAll,
+			errs:        errs,
+		},
+		{
+			label:       "any-empty",
+			aggregation: errbatch.AggregateAny,
+		},
+		{
+			label:       "any",
+			aggregation: errbatch.AggregateAny,
+			errs:        errs,
+			expected:    result{timeout: true, temporary: true, retryable: true},
+		},
+		{
+			label:       "any-none",
+			aggregation: errbatch.AggregateAny,
+			errs:        errs[1:],
+			expected:    result{timeout: true},
+		},
+	} {
+		t.Run(c.label, func(t *testing.T) {
+			batch := errbatch.New(errbatch.WithAggregation(c.aggregation))
+			for _, err := range c.errs {
+				batch.Add(err)
+			}
+			check(t, batch, c.expected)
+		})
+	}
+}
@@ -0,0 +1,195 @@
+package errbatch
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// TB is the subset of testing.TB used by the testing helpers in this
+// package.
+//
+// It's defined here so that this package does not import testing.
+type TB interface {
+	Helper()
+	Errorf(format string, args ...any)
+	FailNow()
+}
+
+// RequireEmpty fails and stops the test if err contains any error.
+//
+// err is considered empty if it's nil or an empty batch.
+// On failure each contained error is printed on its own line.
+func RequireEmpty(t TB, err error) {
+	t.Helper()
+	if errs := errorsOf(err); len(errs) > 0 {
+		t.Errorf("Expected no errors, got %d:\n%s", len(errs), listErrors(errs))
+		t.FailNow()
+	}
+}
+
+// AssertContains reports a test failure if none of the errors contained in
+// err matches target, as defined by errors.Is.
+//
+// When err is not a batch, it's checked as a single error.
+// On failure each contained error is printed on its own line.
+//
+// It returns whether the assertion passed.
+func AssertContains(t TB, err error, target error) bool {
+	t.Helper()
+	errs := errorsOf(err)
+	for _, e := range errs {
+		if errors.Is(e, target) {
+			return true
+		}
+	}
+	t.Errorf(
+		"Expected an error matching %v, got %d:\n%s",
+		target,
+		len(errs),
+		listErrors(errs),
+	)
+	return false
+}
+
+// Report reports a test failure with all the errors in the batch,
+// each on its own line, if the batch is not empty,
+// so tests accumulating multiple assertion failures into a batch can report
+// them all legibly at the end:
+//
+//	var batch errbatch.ErrBatch
+//	for _, c := range cases {
+//		batch.AddNamed(c.name, check(c))
+//	}
+//	batch.Report(t)
+//
+// Unlike RequireEmpty it does not stop the test.
+// It returns whether the batch is empty.
+func (eb *ErrBatch) Report(t TB) bool {
+	t.Helper()
+	errs := eb.GetErrors()
+	if len(errs) == 0 {
+		return true
+	}
+	t.Errorf("%d error(s):\n%s", len(errs), listErrors(errs))
+	return false
+}
+
+// AssertMessages reports a test failure if the messages of the errors
+// contained in err are not want, in the same order,
+// instead of comparing the full message of the batch,
+// which is brittle to the header and separator.
+//
+// When err is not a batch, it's checked as a single error.
+// On failure a line-based diff is printed,
+// with the missing messages prefixed by "-" and the unexpected ones by "+".
+//
+// It returns whether the assertion passed.
+func AssertMessages(t TB, err error, want []string) bool {
+	t.Helper()
+	return assertMessages(t, messagesOf(err), want, "")
+}
+
+// AssertMessagesUnordered is the same as AssertMessages,
+// except that the order of the messages is ignored.
+//
+// The number of occurrences of each message still needs to match.
+func AssertMessagesUnordered(t TB, err error, want []string) bool {
+	t.Helper()
+	got := messagesOf(err)
+	slices.Sort(got)
+	want = slices.Sorted(slices.Values(want))
+	return assertMessages(t, got, want, "unordered ")
+}
+
+func assertMessages(t TB, got, want []string, kind string) bool {
+	t.Helper()
+	if slices.Equal(got, want) {
+		return true
+	}
+	t.Errorf(
+		"Expected %d %smessage(s), got %d (-want +got):\n%s",
+		len(want),
+		kind,
+		len(got),
+		diffLines(want, got),
+	)
+	return false
+}
+
+// messagesOf returns the messages of the errors contained in err.
+func messagesOf(err error) []string {
+	errs := errorsOf(err)
+	msgs := make([]string, len(errs))
+	for i, e := range errs {
+		msgs[i] = errorMessage(e)
+	}
+	return msgs
+}
+
+// diffLines returns the line-based diff from a to b,
+// based on their longest common subsequence.
+func diffLines(a, b []string) string {
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and
+	// b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var lines []string
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			lines = append(lines, "\t  "+a[i])
+			i++
+			j++
+		case j == len(b) || i < len(a) && lcs[i+1][j] >= lcs[i][j+1]:
+			lines = append(lines, "\t- "+a[i])
+			i++
+		default:
+			lines = append(lines, "\t+ "+b[j])
+			j++
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// errorsOf returns the underlying errors if err is a batch,
+// or err itself otherwise.
+func errorsOf(err error) []error {
+	if err == nil {
+		return nil
+	}
+	entries, ok := flatten(err, callSite{}.newEntry)
+	if !ok {
+		return []error{err}
+	}
+	errs := make([]error, len(entries))
+	for i, e := range entries {
+		errs[i] = e.err
+	}
+	return errs
+}
+
+func listErrors(errs []error) string {
+	var sb strings.Builder
+	for i, err := range errs {
+		if i > 0 {
+			sb.WriteByte('\n')
+		}
+		fmt.Fprintf(&sb, "\t[%d] %v", i, err)
+	}
+	return sb.String()
+}
@@ -0,0 +1,188 @@
+package errbatch_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/fishy/errbatch"
+)
+
+// Make sure testing.TB satisfies errbatch.TB.
+var _ errbatch.TB = testing.TB(nil)
+
+type fakeTB struct {
+	msgs   []string
+	failed bool
+}
+
+func (*fakeTB) Helper() {}
+
+func (tb *fakeTB) Errorf(format string, args ...any) {
+	tb.msgs = append(tb.msgs, fmt.Sprintf(format, args...))
+}
+
+func (tb *fakeTB) FailNow() {
+	tb.failed = true
+}
+
+func TestRequireEmpty(t *testing.T) {
+	var batch errbatch.ErrBatch
+	for _, c := range []struct {
+		label    string
+		err      error
+		expected string
+	}{
+		{
+			label: "nil",
+		},
+		{
+			label: "empty-batch",
+			err:   &batch,
+		},
+		{
+			label:    "single",
+			err:      errors.New("foo"),
+			expected: "Expected no errors, got 1:\n\t[0] foo",
+		},
+		{
+			label:    "batch",
+			err:      errbatch.FromSlice([]error{errors.New("foo"), errors.New("bar")}),
+			expected: "Expected no errors, got 2:\n\t[0] foo\n\t[1] bar",
+		},
+	} {
+		t.Run(c.label, func(t *testing.T) {
+			var tb fakeTB
+			errbatch.RequireEmpty(&tb, c.err)
+			if c.expected == "" {
+				if len(tb.msgs) != 0 || tb.failed {
+					t.Errorf("Expected to pass, got %q", tb.msgs)
+				}
+				return
+			}
+			if !tb.failed {
+				t.Error("Expected FailNow to be called")
+			}
+			if len(tb.msgs) != 1 || tb.msgs[0] != c.expected {
+				t.Errorf("Expected %q, got %q", c.expected, tb.msgs)
+			}
+		})
+	}
+}
+
+func TestAssertContains(t *testing.T) {
+	batch := errbatch.FromSlice([]error{
+		errors.New("foo"),
+		fmt.Errorf("bar: %w", io.EOF),
+	})
+
+	var tb fakeTB
+	if !errbatch.AssertContains(&tb, batch, io.EOF) {
+		t.Errorf("Expected to pass, got %q", tb.msgs)
+	}
+	if !errbatch.AssertContains(&tb, io.EOF, io.EOF) {
+		t.Errorf("Expected to pass, got %q", tb.msgs)
+	}
+
+	if errbatch.AssertContains(&tb, batch, io.ErrUnexpectedEOF) {
+		t.Error("Expected to fail")
+	}
+	const expected = "Expected an error matching unexpected EOF, got 2:\n\t[0] foo\n\t[1] bar: EOF"
+	if len(tb.msgs) != 1 || tb.msgs[0] != expected {
+		t.Errorf("Expected %q, got %q", expected, tb.msgs)
+	}
+	if tb.failed {
+		t.Error("Expected FailNow not to be called")
+	}
+}
+
+func TestReport(t *testing.T) {
+	var batch errbatch.ErrBatch
+	var tb fakeTB
+	if !batch.Report(&tb) || len(tb.msgs) != 0 {
+		t.Errorf("Expected empty batch to pass, got %q", tb.msgs)
+	}
+
+	batch.AddNamed("case 1", errors.New("foo"))
+	batch.AddNamed("case 2", errors.New("bar"))
+	if batch.Report(&tb) {
+		t.Error("Expected Report to return false")
+	}
+	const expected = "2 error(s):\n\t[0] case 1: foo\n\t[1] case 2: bar"
+	if len(tb.msgs) != 1 || tb.msgs[0] != expected {
+		t.Errorf("Expected %q, got %q", expected, tb.msgs)
+	}
+	if tb.failed {
+		t.Error("Expected FailNow not to be called")
+	}
+}
+
+func TestAssertMessages(t *testing.T) {
+	batch := errbatch.FromSlice([]error{
+		errors.New("foo"),
+		errors.New("bar"),
+		errors.New("baz"),
+	})
+	for _, c := range []struct {
+		label     string
+		err       error
+		want      []string
+		unordered bool
+		expected  string
+	}{
+		{
+			label: "nil",
+			err:   nil,
+		},
+		{
+			label: "single",
+			err:   errors.New("foo"),
+			want:  []string{"foo"},
+		},
+		{
+			label: "sequence",
+			err:   batch,
+			want:  []string{"foo", "bar", "baz"},
+		},
+		{
+			label:    "sequence-order",
+			err:      batch,
+			want:     []string{"bar", "foo", "baz"},
+			expected: "Expected 3 message(s), got 3 (-want +got):\n\t- bar\n\t  foo\n\t+ bar\n\t  baz",
+		},
+		{
+			label:     "set",
+			err:       batch,
+			want:      []string{"baz", "foo", "bar"},
+			unordered: true,
+		},
+		{
+			label:     "set-mismatch",
+			err:       batch,
+			want:      []string{"foo", "qux", "bar", "foo"},
+			unordered: true,
+			expected:  "Expected 4 unordered message(s), got 3 (-want +got):\n\t  bar\n\t- foo\n\t+ baz\n\t  foo\n\t- qux",
+		},
+	} {
+		t.Run(c.label, func(t *testing.T) {
+			var tb fakeTB
+			assert := errbatch.AssertMessages
+			if c.unordered {
+				assert = errbatch.AssertMessagesUnordered
+			}
+			if passed := assert(&tb, c.err, c.want); passed != (c.expected == "") {
+				t.Errorf("Expected passed to be %v, got %v", c.expected == "", passed)
+			}
+			if c.expected == "" {
+				if len(tb.msgs) != 0 {
+					t.Errorf("Expected to pass, got %q", tb.msgs)
+				}
+				return
+			}
+			if len(tb.msgs) != 1 || tb.msgs[0] != c.expected {
+				t.Errorf("Expected %q, got %q", c.expected, tb.msgs)
+			}
+		})
+	}
+}
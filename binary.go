@@ -0,0 +1,112 @@
+package errbatch
+
+import (
+	"encoding"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"runtime"
+)
+
+// Make sure *ErrBatch satisfies encoding.BinaryMarshaler and
+// encoding.BinaryUnmarshaler interfaces.
+var (
+	_ encoding.BinaryMarshaler   = (*ErrBatch)(nil)
+	_ encoding.BinaryUnmarshaler = (*ErrBatch)(nil)
+)
+
+// binaryVersion is the version of the framing used by MarshalBinary.
+const binaryVersion = 1
+
+// DecodedError is the error type of the errors decoded by
+// (*ErrBatch).UnmarshalBinary, UnmarshalCBOR and UnmarshalMsgpack.
+//
+// The original error values can't be reconstructed from the serialized form,
+// so DecodedError carries what was serialized instead.
+type DecodedError struct {
+	// The message of the original error.
+	Message string
+
+	// The type name of the original error, e.g. "*errors.errorString".
+	TypeName string
+
+	// The stack captured when the original error was added (see WithStack),
+	// one "function file:line" string per frame.
+	// It's nil if the stack was not captured.
+	Stack []string
+}
+
+func (e *DecodedError) Error() string {
+	return e.Message
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+//
+// The batch is encoded in a compact framing of the traceparent set by
+// SetTraceparent, and the message, type name, trace ID and stack captured by
+// WithStack (if any) of each of the underlying errors.
+// Like MarshalJSON, the messages are redacted by WithRedactor,
+// unless the batch is created with WithLosslessEncoding,
+// so it can be restored losslessly by UnmarshalBinary.
+func (eb *ErrBatch) MarshalBinary() ([]byte, error) {
+	return eb.appendWire([]byte{binaryVersion}, binaryFormat{}), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+//
+// It replaces the errors and traceparent of the batch with the ones decoded
+// from data produced by MarshalBinary,
+// the decoded errors are of type *DecodedError.
+// The options of the batch are kept and applied to the decoded errors
+// (e.g. WithIgnore, WithDedup and the retention policies), the same as Add.
+// On errors the batch is not changed.
+func (eb *ErrBatch) UnmarshalBinary(data []byte) error {
+	if len(data) == 0 || data[0] != binaryVersion {
+		return errors.New("errbatch: unsupported binary encoding")
+	}
+	if err := eb.decodeWire(data[1:], binaryFormat{}); err != nil {
+		return fmt.Errorf("errbatch: malformed binary encoding: %w", err)
+	}
+	return nil
+}
+
+// binaryFormat is the wireFormat used by MarshalBinary,
+// with both array and string lengths encoded as uvarints.
+type binaryFormat struct{}
+
+func (binaryFormat) appendArray(buf []byte, n int) []byte {
+	return binary.AppendUvarint(buf, uint64(n))
+}
+
+func (binaryFormat) appendString(buf []byte, s string) []byte {
+	buf = binary.AppendUvarint(buf, uint64(len(s)))
+	return append(buf, s...)
+}
+
+func (binaryFormat) readArray(d *wireDecoder) int {
+	return d.count(d.uvarint())
+}
+
+func (binaryFormat) readString(d *wireDecoder) string {
+	return string(d.next(d.count(d.uvarint())))
+}
+
+// stackStrings returns the stack of the entry as "function file:line"
+// strings, either captured by WithStack or carried by a DecodedError.
+func (e entry) stackStrings() []string {
+	if len(e.stack) == 0 {
+		if decoded, ok := e.err.(*DecodedError); ok {
+			return decoded.Stack
+		}
+		return nil
+	}
+	var stack []string
+	frames := runtime.CallersFrames(e.stack)
+	for {
+		frame, more := frames.Next()
+		stack = append(stack, fmt.Sprintf("%s %s:%d", frame.Function, frame.File, frame.Line))
+		if !more {
+			return stack
+		}
+	}
+}
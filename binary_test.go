@@ -0,0 +1,173 @@
+package errbatch_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/fishy/errbatch"
+)
+
+func TestMarshalBinary(t *testing.T) {
+	batch := errbatch.New(errbatch.WithStack())
+	batch.Add(errors.New("foo"))
+	batch.AddCoded("code", customError{})
+	if err := batch.SetTraceparent(traceparent); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := batch.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded errbatch.ErrBatch
+	if err := decoded.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+
+	if decoded.Error() != batch.Error() {
+		t.Errorf("Expected %q, got %q", batch.Error(), decoded.Error())
+	}
+	if decoded.Traceparent() != traceparent {
+		t.Errorf("Expected traceparent %q, got %q", traceparent, decoded.Traceparent())
+	}
+	errs := decoded.GetErrors()
+	types := []string{"*errors.errorString", "errbatch_test.customError"}
+	for i, err := range errs {
+		var de *errbatch.DecodedError
+		if !errors.As(err, &de) {
+			t.Fatalf("Expected *errbatch.DecodedError, got %#v", err)
+		}
+		if de.TypeName != types[i] {
+			t.Errorf("Expected type name %q, got %q", types[i], de.TypeName)
+		}
+		if len(de.Stack) == 0 || !strings.Contains(de.Stack[0], "TestMarshalBinary") {
+			t.Errorf("Expected stack starting with TestMarshalBinary, got %#v", de.Stack)
+		}
+	}
+
+	t.Run("round-trip", func(t *testing.T) {
+		again, err := decoded.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(again) != string(data) {
+			t.Errorf("Expected %q, got %q", data, again)
+		}
+	})
+}
+
+func TestUnmarshalBinaryMalformed(t *testing.T) {
+	var batch errbatch.ErrBatch
+	batch.Add(errors.New("foo"))
+	data, err := batch.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range []struct {
+		label string
+		data  []byte
+	}{
+		{
+			label: "empty",
+		},
+		{
+			label: "version",
+			data:  append([]byte{0}, data[1:]...),
+		},
+		{
+			label: "truncated",
+			data:  data[:len(data)-1],
+		},
+		{
+			label: "trailing",
+			data:  append(data[:len(data):len(data)], 0),
+		},
+	} {
+		t.Run(c.label, func(t *testing.T) {
+			var target errbatch.ErrBatch
+			target.Add(errors.New("bar"))
+			expected := target.Error()
+			if err := target.UnmarshalBinary(c.data); err == nil {
+				t.Error("Expected error, got nil")
+			}
+			if target.Error() != expected {
+				t.Errorf("Expected %q, got %q", expected, target.Error())
+			}
+		})
+	}
+}
+
+func TestMarshalBinaryRedactor(t *testing.T) {
+	redact := errbatch.WithRedactor(func(msg string) string {
+		return strings.ReplaceAll(msg, "hunter2", "***")
+	})
+	for _, c := range []struct {
+		label    string
+		opts     []errbatch.Option
+		expected string
+	}{
+		{
+			label:    "default",
+			opts:     []errbatch.Option{redact},
+			expected: "password: ***",
+		},
+		{
+			label:    "lossless",
+			opts:     []errbatch.Option{redact, errbatch.WithLosslessEncoding()},
+			expected: "password: hunter2",
+		},
+	} {
+		t.Run(c.label, func(t *testing.T) {
+			batch := errbatch.New(c.opts...)
+			batch.Add(errors.New("password: hunter2"))
+			for _, marshal := range []func() ([]byte, error){
+				batch.MarshalBinary,
+				batch.MarshalCBOR,
+				batch.MarshalMsgpack,
+			} {
+				data, err := marshal()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !strings.Contains(string(data), c.expected) {
+					t.Errorf("Expected %q in %q", c.expected, data)
+				}
+			}
+		})
+	}
+}
+
+func TestUnmarshalBinaryOptions(t *testing.T) {
+	var batch errbatch.ErrBatch
+	batch.Add(errors.New("foo"))
+	batch.Add(errors.New("foo"))
+	batch.Add(errors.New("bar"))
+	data, err := batch.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ordered := errbatch.New(errbatch.WithSequenceOrder())
+	ordered.Add(errors.New("foobar"))
+
+	decoded := errbatch.New(errbatch.WithDedup(errbatch.DedupByMessage))
+	if err := decoded.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	const expected = "errbatch: total 2 error(s) in this batch: foo; bar"
+	if decoded.Error() != expected {
+		t.Errorf("Expected %q, got %q", expected, decoded.Error())
+	}
+
+	// Decoded errors are not assigned sequence numbers without
+	// WithSequenceOrder, so they are ordered before the ones with.
+	merged := errbatch.New(errbatch.WithSequenceOrder())
+	merged.Add(ordered)
+	merged.Add(decoded)
+	const expectedMerged = "errbatch: total 3 error(s) in this batch: foo; bar; foobar"
+	if merged.Error() != expectedMerged {
+		t.Errorf("Expected %q, got %q", expectedMerged, merged.Error())
+	}
+}
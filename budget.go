@@ -0,0 +1,101 @@
+package errbatch
+
+import (
+	"sync"
+	"time"
+)
+
+// Budget is an error budget over a sliding time window.
+//
+// It records the results (successes and failures) of operations,
+// and is only considered exceeded when the ratio of failures among all the
+// results recorded within the window is over the budget.
+// It's useful for best-effort jobs (e.g. background syncs),
+// where occasional failures are expected and should not be reported.
+//
+// Budget is safe to be used concurrently.
+type Budget struct {
+	window time.Duration
+	ratio  float64
+
+	mu      sync.Mutex
+	results []budgetResult
+}
+
+type budgetResult struct {
+	at  time.Time
+	err error
+}
+
+// NewBudget creates a new Budget.
+//
+// ratio is the maximum ratio of failures (between 0 and 1) allowed within
+// window.
+func NewBudget(window time.Duration, ratio float64) *Budget {
+	return &Budget{
+		window: window,
+		ratio:  ratio,
+	}
+}
+
+// Record records the result of an operation.
+//
+// Nil err is recorded as a success, non-nil err as a failure.
+func (b *Budget) Record(err error) {
+	now := time.Now()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.prune(now)
+	b.results = append(b.results, budgetResult{at: now, err: err})
+}
+
+// prune removes the results outside of the window.
+//
+// It must be called with b.mu held.
+func (b *Budget) prune(now time.Time) {
+	cutoff := now.Add(-b.window)
+	i := 0
+	for i < len(b.results) && !b.results[i].at.After(cutoff) {
+		i++
+	}
+	if i > 0 {
+		b.results = append(b.results[:0], b.results[i:]...)
+	}
+}
+
+// failures returns the failures within the window and the total number of
+// results within the window.
+func (b *Budget) failures() (errs []error, total int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.prune(time.Now())
+	for _, r := range b.results {
+		if r.err != nil {
+			errs = append(errs, r.err)
+		}
+	}
+	return errs, len(b.results)
+}
+
+// Exceeded reports whether the ratio of failures within the window is over
+// the budget.
+func (b *Budget) Exceeded() bool {
+	errs, total := b.failures()
+	return exceeded(len(errs), total, b.ratio)
+}
+
+func exceeded(failures, total int, ratio float64) bool {
+	return total > 0 && float64(failures)/float64(total) > ratio
+}
+
+// Compile compiles the failures within the window the same as
+// ErrBatch.Compile when the budget is exceeded.
+// Otherwise it returns nil.
+func (b *Budget) Compile() error {
+	errs, total := b.failures()
+	if !exceeded(len(errs), total, b.ratio) {
+		return nil
+	}
+	return FromSlice(errs).Compile()
+}
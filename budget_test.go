@@ -0,0 +1,48 @@
+package errbatch_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/fishy/errbatch"
+)
+
+func TestBudget(t *testing.T) {
+	const window = 50 * time.Millisecond
+	budget := errbatch.NewBudget(window, 0.5)
+	if budget.Exceeded() {
+		t.Error("Expected empty budget to not be exceeded")
+	}
+
+	err0 := errors.New("foo")
+	budget.Record(nil)
+	budget.Record(err0)
+	if budget.Exceeded() {
+		t.Error("Expected budget to not be exceeded at exactly the ratio")
+	}
+	if err := budget.Compile(); err != nil {
+		t.Errorf("Expected nil, got %#v", err)
+	}
+
+	err1 := errors.New("bar")
+	budget.Record(err1)
+	if !budget.Exceeded() {
+		t.Error("Expected budget to be exceeded")
+	}
+	var batch *errbatch.ErrBatch
+	if err := budget.Compile(); !errors.As(err, &batch) || len(batch.GetErrors()) != 2 {
+		t.Errorf("Expected a batch of 2 errors, got %v", err)
+	}
+
+	time.Sleep(window * 2)
+	if budget.Exceeded() {
+		t.Error("Expected results outside of the window to be dropped")
+	}
+	budget.Record(nil)
+	budget.Record(err0)
+	budget.Record(nil)
+	if err := budget.Compile(); err != nil {
+		t.Errorf("Expected nil, got %#v", err)
+	}
+}
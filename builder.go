@@ -0,0 +1,77 @@
+package errbatch
+
+import (
+	"fmt"
+)
+
+// Builder builds a batch with chainable methods.
+//
+// Unlike ErrBatch, Builder is not an error itself.
+// It's only used to construct errors,
+// and its Compile returns an immutable result,
+// so the result is never changed by further calls to the Builder:
+//
+//	return errbatch.NewBuilder().
+//		Add(validateName(req.Name)).
+//		AddNamed("age", validateAge(req.Age)).
+//		Wrap(db.Ping(), "database unavailable").
+//		Compile()
+//
+// The zero value of Builder is valid and ready to use.
+type Builder struct {
+	batch ErrBatch
+}
+
+// NewBuilder creates a new Builder with the given options.
+func NewBuilder(opts ...Option) *Builder {
+	return &Builder{
+		batch: ErrBatch{
+			opts: New(opts...).opts,
+		},
+	}
+}
+
+// Add adds an error, the same as ErrBatch.Add.
+func (b *Builder) Add(err error) *Builder {
+	b.batch.add(err, nil)
+	return b
+}
+
+// AddNamed adds an error keyed by name, the same as ErrBatch.AddNamed.
+func (b *Builder) AddNamed(name string, err error) *Builder {
+	b.batch.add(err, func(e *entry) {
+		e.err = &namedError{name: name, err: e.err}
+	})
+	return b
+}
+
+// Wrap adds err wrapped with msg,
+// in the form of "msg: original error message".
+//
+// If err is a batch,
+// each of its underlying errors is wrapped with msg instead,
+// the same as AddNamed.
+//
+// The wrapped error can still be matched by errors.Is and errors.As.
+// Nil error will be skipped.
+func (b *Builder) Wrap(err error, msg string) *Builder {
+	b.batch.add(err, func(e *entry) {
+		e.err = fmt.Errorf("%s: %w", msg, e.err)
+	})
+	return b
+}
+
+// Len returns the number of errors added so far.
+func (b *Builder) Len() int {
+	return len(b.batch.entries)
+}
+
+// Compile compiles the errors added so far, the same as
+// ErrBatch.CompileValue.
+//
+// The Builder can still be used after Compile,
+// without affecting the returned error.
+// The first Compile delivers the result to the Notifier set by WithNotifier.
+func (b *Builder) Compile() error {
+	return b.batch.CompileValue()
+}
@@ -0,0 +1,75 @@
+package errbatch_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+	"testing"
+
+	"github.com/fishy/errbatch"
+)
+
+func TestBuilder(t *testing.T) {
+	var builder errbatch.Builder
+	if err := builder.Compile(); err != nil {
+		t.Errorf("Expected nil, got %#v", err)
+	}
+
+	err := builder.
+		Add(errors.New("foo")).
+		Add(nil).
+		AddNamed("name", errors.New("bar")).
+		Wrap(io.EOF, "read").
+		Wrap(nil, "skipped").
+		Compile()
+	expected := "errbatch: total 3 error(s) in this batch: foo; name: bar; read: EOF"
+	if err.Error() != expected {
+		t.Errorf("Expected %q, got %q", expected, err.Error())
+	}
+	if !errors.Is(err, io.EOF) {
+		t.Errorf("Expected errors.Is(%v, io.EOF) to be true", err)
+	}
+
+	builder.Add(errors.New("foobar"))
+	if builder.Len() != 4 {
+		t.Errorf("Expected 4 errors, got %d", builder.Len())
+	}
+	if err.Error() != expected {
+		t.Errorf("Expected compiled error to be unchanged %q, got %q", expected, err.Error())
+	}
+}
+
+func TestBuilderCaller(t *testing.T) {
+	builder := errbatch.NewBuilder(errbatch.WithCaller())
+	builder.Add(errors.New("foo"))
+	_, _, line0, _ := runtime.Caller(0)
+	builder.Wrap(errors.New("bar"), "wrapped")
+	_, _, line1, _ := runtime.Caller(0)
+
+	expected := fmt.Sprintf(
+		"errbatch: total 2 error(s) in this batch:\nbuilder_test.go:%d: foo\nbuilder_test.go:%d: wrapped: bar",
+		line0-1,
+		line1-1,
+	)
+	if actual := fmt.Sprintf("%+v", builder.Compile()); actual != expected {
+		t.Errorf("Expected %q, got %q", expected, actual)
+	}
+}
+
+func TestBuilderWrapBatch(t *testing.T) {
+	var batch errbatch.ErrBatch
+	batch.Add(errors.New("a"))
+	batch.Add(io.EOF)
+
+	err := errbatch.NewBuilder().
+		Wrap(batch.Compile(), "database unavailable").
+		Compile()
+	const expected = "errbatch: total 2 error(s) in this batch: database unavailable: a; database unavailable: EOF"
+	if err.Error() != expected {
+		t.Errorf("Expected %q, got %q", expected, err.Error())
+	}
+	if !errors.Is(err, io.EOF) {
+		t.Errorf("Expected errors.Is(%v, io.EOF) to be true", err)
+	}
+}
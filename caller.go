@@ -0,0 +1,201 @@
+package errbatch
+
+import (
+	"bytes"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// captureDisabled is the global toggle set by SetCaptureEnabled.
+var captureDisabled atomic.Bool
+
+// SetCaptureEnabled globally enables or disables capturing call sites and
+// stacks (see WithCaller and WithStack), which are enabled by default.
+//
+// It allows the same code, with batches created with WithCaller or WithStack,
+// to run with rich diagnostics in staging,
+// and with near-zero overhead in production hot paths by disabling capturing
+// at startup:
+//
+//	if env == "production" {
+//		errbatch.SetCaptureEnabled(false)
+//	}
+//
+// When disabled, the cost on every Add call is a single atomic load,
+// and the errors added are not annotated with call sites or stacks.
+// It does not affect the errors already added.
+// It's safe to be called concurrently.
+func SetCaptureEnabled(enabled bool) {
+	captureDisabled.Store(!enabled)
+}
+
+// location returns the "file.go:line" of the call site that added the entry,
+// or an empty string if it's not captured.
+func (e entry) location() string {
+	if e.pc == 0 {
+		return ""
+	}
+	frame, _ := runtime.CallersFrames([]uintptr{e.pc}).Next()
+	if frame.File == "" {
+		return ""
+	}
+	return filepath.Base(frame.File) + ":" + strconv.Itoa(frame.Line)
+}
+
+// WithCaller enables capturing the call site (file and line) of each Add
+// call.
+//
+// The captured call sites are included in the verbose (%+v) output of the
+// batch.
+//
+// Capturing call sites has a small cost on every Add call,
+// which can be globally disabled by SetCaptureEnabled.
+func WithCaller() Option {
+	return func(o *options) {
+		o.caller = true
+	}
+}
+
+// WithCallerSkip makes the captured call sites skip n additional levels of
+// callers.
+//
+// It's useful when Add is wrapped in helper functions,
+// to attribute the errors to the callers of the helpers instead.
+// For example, when Add is called inside a helper,
+// WithCallerSkip(1) attributes the errors to the caller of the helper.
+//
+// It has no effect unless WithCaller is also used.
+func WithCallerSkip(n int) Option {
+	return func(o *options) {
+		o.callerSkip = n
+	}
+}
+
+// WithStack enables capturing the stack of each Add call.
+//
+// The captured stacks can be retrieved via Frames.
+//
+// Capturing stacks is more expensive than capturing call sites via
+// WithCaller, so it's not recommended in hot paths,
+// unless globally disabled by SetCaptureEnabled.
+// WithCallerSkip also applies to the captured stacks.
+func WithStack() Option {
+	return func(o *options) {
+		o.stack = true
+	}
+}
+
+// WithDebug enables recording the goroutine and the call site (as WithCaller)
+// of each Add call,
+// and the verbose (%+v) output of the batch prefixes each error with them,
+// e.g. "[goroutine 42] worker.go:17: connection refused",
+// to diagnose who added a mystery error in large concurrent codebases.
+//
+// Getting the goroutine ID is expensive (it formats the stack of the current
+// goroutine), so it's only intended for debugging.
+func WithDebug() Option {
+	return func(o *options) {
+		o.debug = true
+		o.caller = true
+	}
+}
+
+// goroutineID returns the ID of the current goroutine,
+// or 0 if it can't be parsed.
+func goroutineID() uint64 {
+	var buf [64]byte
+	// The first line of the stack is "goroutine 42 [running]:".
+	b := bytes.TrimPrefix(buf[:runtime.Stack(buf[:], false)], []byte("goroutine "))
+	if i := bytes.IndexByte(b, ' '); i > 0 {
+		b = b[:i]
+	}
+	id, _ := strconv.ParseUint(string(b), 10, 64)
+	return id
+}
+
+// maxStackDepth is the max number of frames captured by WithStack.
+const maxStackDepth = 32
+
+// callSite is the call site adding errors,
+// captured according to the options.
+type callSite struct {
+	pc        uintptr
+	stack     []uintptr
+	goroutine uint64
+
+	// Whether to assign sequence numbers (see WithSequenceOrder) and
+	// timestamps (see WithTimestamps).
+	sequence  bool
+	timestamp bool
+}
+
+// callSite captures the call site adding errors according to the options.
+//
+// depth is the number of unexported functions between the exported method
+// adding errors (e.g. Add) and callSite,
+// 0 means it's called directly from the exported method.
+func (opts *options) callSite(depth int) callSite {
+	site := callSite{
+		sequence:  opts.sequenceOrder,
+		timestamp: opts.timestamps,
+	}
+	if !opts.caller && !opts.stack || captureDisabled.Load() {
+		return site
+	}
+	if opts.debug {
+		site.goroutine = goroutineID()
+	}
+	// 0: runtime.Callers, 1: callSite, 2: exported method (e.g. Add), 3: its caller.
+	skip := 3 + depth + opts.callerSkip
+	if opts.caller {
+		var pcs [1]uintptr
+		if runtime.Callers(skip, pcs[:]) > 0 {
+			site.pc = pcs[0]
+		}
+	}
+	if opts.stack {
+		var pcs [maxStackDepth]uintptr
+		n := runtime.Callers(skip, pcs[:])
+		site.stack = append([]uintptr(nil), pcs[:n]...)
+	}
+	return site
+}
+
+// newEntry creates a new entry for err added from the call site,
+// with the next sequence number and the current time if needed.
+func (site callSite) newEntry(err error) entry {
+	e := entry{
+		err:   err,
+		pc:    site.pc,
+		stack: site.stack,
+	}
+	if site.goroutine == 0 && !site.sequence && !site.timestamp {
+		return e
+	}
+	meta := &entryMeta{
+		goroutine: site.goroutine,
+	}
+	if site.sequence {
+		meta.seq = nextSeq()
+	}
+	if site.timestamp {
+		meta.at = time.Now()
+	}
+	e.meta = meta
+	return e
+}
+
+// Frames returns the stack captured when the i-th error was added,
+// in the same order as GetErrors.
+//
+// It returns nil when i is out of range,
+// or the batch was not created with WithStack.
+func (eb *ErrBatch) Frames(i int) *runtime.Frames {
+	if i < 0 || i >= len(eb.entries) || len(eb.entries[i].stack) == 0 {
+		return nil
+	}
+	return runtime.CallersFrames(eb.entries[i].stack)
+}
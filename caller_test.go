@@ -0,0 +1,169 @@
+package errbatch_test
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/fishy/errbatch"
+)
+
+func TestCaller(t *testing.T) {
+	batch := errbatch.New(errbatch.WithCaller())
+	batch.Add(errors.New("foo"))
+	_, _, line0, _ := runtime.Caller(0)
+	batch.AddCoded("code", errors.New("bar"))
+	_, _, line1, _ := runtime.Caller(0)
+
+	expected := fmt.Sprintf(
+		"errbatch: total 2 error(s) in this batch:\ncaller_test.go:%d: foo\ncaller_test.go:%d: bar",
+		line0-1,
+		line1-1,
+	)
+	if actual := fmt.Sprintf("%+v", batch); actual != expected {
+		t.Errorf("Expected %q, got %q", expected, actual)
+	}
+
+	expected = "errbatch: total 2 error(s) in this batch: foo; bar"
+	if actual := fmt.Sprintf("%v", batch); actual != expected {
+		t.Errorf("Expected %q, got %q", expected, actual)
+	}
+
+	var another errbatch.ErrBatch
+	another.Add(batch)
+	another.Add(errors.New("foobar"))
+	expected = fmt.Sprintf(
+		"errbatch: total 3 error(s) in this batch:\ncaller_test.go:%d: foo\ncaller_test.go:%d: bar\nfoobar",
+		line0-1,
+		line1-1,
+	)
+	if actual := fmt.Sprintf("%+v", &another); actual != expected {
+		t.Errorf("Expected call sites to be kept when flattened, got %q", actual)
+	}
+}
+
+func addHelper(batch *errbatch.ErrBatch, err error) {
+	batch.Add(err)
+}
+
+func TestCallerSkip(t *testing.T) {
+	batch := errbatch.New(errbatch.WithCaller(), errbatch.WithCallerSkip(1))
+	addHelper(batch, errors.New("foo"))
+	_, _, line, _ := runtime.Caller(0)
+
+	expected := fmt.Sprintf(
+		"errbatch: total 1 error(s) in this batch:\ncaller_test.go:%d: foo",
+		line-1,
+	)
+	if actual := fmt.Sprintf("%+v", batch); actual != expected {
+		t.Errorf("Expected %q, got %q", expected, actual)
+	}
+}
+
+func TestFrames(t *testing.T) {
+	var batch errbatch.ErrBatch
+	batch.Add(errors.New("foo"))
+	if frames := batch.Frames(0); frames != nil {
+		t.Errorf("Expected nil frames without WithStack, got %v", frames)
+	}
+
+	stacked := errbatch.New(errbatch.WithStack())
+	stacked.Add(errors.New("foo"))
+	_, _, line, _ := runtime.Caller(0)
+	stacked.Add(&batch)
+	if frames := stacked.Frames(1); frames != nil {
+		t.Errorf("Expected nil frames for errors added to another batch, got %v", frames)
+	}
+	if frames := stacked.Frames(2); frames != nil {
+		t.Errorf("Expected nil frames for out of range index, got %v", frames)
+	}
+
+	frames := stacked.Frames(0)
+	if frames == nil {
+		t.Fatal("Expected non-nil frames")
+	}
+	frame, _ := frames.Next()
+	if frame.Function != "github.com/fishy/errbatch_test.TestFrames" {
+		t.Errorf("Expected first frame in TestFrames, got %q", frame.Function)
+	}
+	if frame.Line != line-1 {
+		t.Errorf("Expected first frame at line %d, got %d", line-1, frame.Line)
+	}
+}
+
+func TestSetCaptureEnabled(t *testing.T) {
+	errbatch.SetCaptureEnabled(false)
+	t.Cleanup(func() {
+		errbatch.SetCaptureEnabled(true)
+	})
+
+	batch := errbatch.New(errbatch.WithCaller(), errbatch.WithStack())
+	batch.Add(errors.New("foo"))
+	errbatch.SetCaptureEnabled(true)
+	batch.Add(errors.New("bar"))
+
+	if frames := batch.Frames(0); frames != nil {
+		t.Errorf("Expected nil frames when disabled, got %v", frames)
+	}
+	if frames := batch.Frames(1); frames == nil {
+		t.Error("Expected non-nil frames when enabled")
+	}
+	const prefix = "errbatch: total 2 error(s) in this batch:\nfoo\ncaller_test.go:"
+	if actual := fmt.Sprintf("%+v", batch); !strings.HasPrefix(actual, prefix) {
+		t.Errorf("Expected prefix %q, got %q", prefix, actual)
+	}
+}
+
+func TestWithDebug(t *testing.T) {
+	batch := errbatch.New(errbatch.WithDebug())
+	batch.Add(errors.New("foo"))
+	_, _, line0, _ := runtime.Caller(0)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		batch.Add(errors.New("bar"))
+	}()
+	<-done
+
+	lines := strings.Split(fmt.Sprintf("%+v", batch), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 lines, got %q", lines)
+	}
+	var ids [2]int
+	for i, c := range []struct {
+		line   int
+		msg    string
+		actual string
+	}{
+		{line: line0 - 1, msg: "foo", actual: lines[1]},
+		{line: line0 + 4, msg: "bar", actual: lines[2]},
+	} {
+		var msg string
+		format := fmt.Sprintf("[goroutine %%d] caller_test.go:%d: %%s", c.line)
+		if _, err := fmt.Sscanf(c.actual, format, &ids[i], &msg); err != nil || msg != c.msg {
+			t.Errorf("Expected %q to match %q with %q, got %v", c.actual, format, c.msg, err)
+		}
+	}
+	if ids[0] == ids[1] {
+		t.Errorf("Expected different goroutines, got %d", ids[0])
+	}
+}
+
+func TestCallerForeignBatch(t *testing.T) {
+	batch := errbatch.New(errbatch.WithCaller(), errbatch.WithRecursiveFlatten())
+	batch.Add(foreignBatch{errs: []error{errors.New("foo")}})
+	_, _, line0, _ := runtime.Caller(0)
+	batch.Add(errors.Join(errors.New("bar")))
+	_, _, line1, _ := runtime.Caller(0)
+
+	expected := fmt.Sprintf(
+		"errbatch: total 2 error(s) in this batch:\ncaller_test.go:%d: foo\ncaller_test.go:%d: bar",
+		line0-1,
+		line1-1,
+	)
+	if actual := fmt.Sprintf("%+v", batch); actual != expected {
+		t.Errorf("Expected %q, got %q", expected, actual)
+	}
+}
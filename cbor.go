@@ -0,0 +1,103 @@
+package errbatch
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// MarshalCBOR implements the Marshaler interface of
+// github.com/fxamacker/cbor/v2 (and other CBOR libraries using the same
+// interface), without depending on it.
+//
+// The batch is encoded as a CBOR array of the traceparent set by
+// SetTraceparent and an array of the underlying errors,
+// each being an array of its message, type name, trace ID,
+// and stack captured by WithStack:
+//
+//	[traceparent, [[message, type, trace ID, [stack frames...]]...]]
+//
+// Like MarshalBinary, the messages are redacted by WithRedactor,
+// unless the batch is created with WithLosslessEncoding,
+// so it can be restored losslessly by UnmarshalCBOR.
+func (eb *ErrBatch) MarshalCBOR() ([]byte, error) {
+	return eb.appendWire(nil, cborFormat{}), nil
+}
+
+// UnmarshalCBOR implements the Unmarshaler interface of
+// github.com/fxamacker/cbor/v2 (and other CBOR libraries using the same
+// interface), without depending on it.
+//
+// It replaces the errors and traceparent of the batch with the ones decoded
+// from data produced by MarshalCBOR,
+// the decoded errors are of type *DecodedError.
+// The options of the batch are kept and applied to the decoded errors
+// (e.g. WithIgnore, WithDedup and the retention policies), the same as Add.
+// On errors the batch is not changed.
+func (eb *ErrBatch) UnmarshalCBOR(data []byte) error {
+	if err := eb.decodeWire(data, cborFormat{}); err != nil {
+		return fmt.Errorf("errbatch: malformed CBOR encoding: %w", err)
+	}
+	return nil
+}
+
+// CBOR major types, see RFC 8949 section 3.1.
+const (
+	cborTextString = 3
+	cborArray      = 4
+)
+
+// cborFormat is the wireFormat of CBOR (RFC 8949),
+// only definite length text strings and arrays are supported.
+type cborFormat struct{}
+
+func (cborFormat) appendHead(buf []byte, major byte, n int) []byte {
+	major <<= 5
+	switch {
+	case n < 24:
+		return append(buf, major|byte(n))
+	case n <= 0xff:
+		return append(buf, major|24, byte(n))
+	case n <= 0xffff:
+		return binary.BigEndian.AppendUint16(append(buf, major|25), uint16(n))
+	case n <= 0xffffffff:
+		return binary.BigEndian.AppendUint32(append(buf, major|26), uint32(n))
+	default:
+		return binary.BigEndian.AppendUint64(append(buf, major|27), uint64(n))
+	}
+}
+
+func (cborFormat) readHead(d *wireDecoder, major byte) int {
+	b := d.byte()
+	if d.err != nil {
+		return 0
+	}
+	if b>>5 != major {
+		d.fail(fmt.Errorf("expected CBOR major type %d, got %d", major, b>>5))
+		return 0
+	}
+	switch info := b & 0x1f; {
+	case info < 24:
+		return d.count(uint64(info))
+	case info <= 27:
+		return d.count(d.uint(1 << (info - 24)))
+	default:
+		d.fail(fmt.Errorf("unsupported CBOR additional info %d", info))
+		return 0
+	}
+}
+
+func (f cborFormat) appendArray(buf []byte, n int) []byte {
+	return f.appendHead(buf, cborArray, n)
+}
+
+func (f cborFormat) appendString(buf []byte, s string) []byte {
+	return append(f.appendHead(buf, cborTextString, len(s)), s...)
+}
+
+func (f cborFormat) readArray(d *wireDecoder) int {
+	return f.readHead(d, cborArray)
+}
+
+func (f cborFormat) readString(d *wireDecoder) string {
+	return string(d.next(f.readHead(d, cborTextString)))
+}
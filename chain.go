@@ -0,0 +1,78 @@
+package errbatch
+
+import (
+	"errors"
+	"io"
+	"strings"
+)
+
+// chainIndent is the indentation of each level in the wrap chain.
+const chainIndent = "    "
+
+// WithWrapChain makes the verbose forms (e.g. %+v) of the batch also print
+// the wrap chain of each underlying error,
+// one level per line with increasing indentation:
+//
+//	errbatch: total 1 error(s) in this batch:
+//	read config: open file: permission denied
+//	    open file: permission denied
+//	        permission denied
+//
+// Errors wrapping multiple errors (e.g. errors.Join) have each of them
+// printed at the next level.
+// The wrappers added by this package (e.g. AddCoded) are skipped as they do
+// not change the messages.
+// Each level is rendered the same as the underlying errors,
+// e.g. redacted by WithRedactor.
+func WithWrapChain() Option {
+	return func(o *options) {
+		o.wrapChain = true
+	}
+}
+
+// unwrapAll returns the errors directly wrapped by err.
+func unwrapAll(err error) []error {
+	switch e := err.(type) {
+	case interface{ Unwrap() []error }:
+		return e.Unwrap()
+	case *codedError:
+		return unwrapAll(e.err)
+	default:
+		if u := errors.Unwrap(err); u != nil {
+			return []error{u}
+		}
+		return nil
+	}
+}
+
+// WithMaxChainDepth limits the wrap chain printed by WithWrapChain to n
+// levels for each underlying error.
+// Deeper levels are replaced by a single "..." line.
+//
+// It also enables WithWrapChain.
+// n <= 0 means no limit, which is the default.
+func WithMaxChainDepth(n int) Option {
+	return func(o *options) {
+		o.wrapChain = true
+		o.chainDepth = n
+	}
+}
+
+// writeChain writes the errors wrapped by err, starting at level,
+// up to the depth set by WithMaxChainDepth,
+// rendered the same as the underlying errors (e.g. redacted by WithRedactor).
+func writeChain(w io.Writer, opts *options, err error, level int) {
+	for _, wrapped := range unwrapAll(err) {
+		if wrapped == nil {
+			continue
+		}
+		io.WriteString(w, "\n")
+		io.WriteString(w, strings.Repeat(chainIndent, level))
+		if opts.chainDepth > 0 && level > opts.chainDepth {
+			io.WriteString(w, "...")
+			return
+		}
+		io.WriteString(w, opts.render("%v", wrapped))
+		writeChain(w, opts, wrapped, level+1)
+	}
+}
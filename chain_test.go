@@ -0,0 +1,78 @@
+package errbatch_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/fishy/errbatch"
+)
+
+func TestWithWrapChain(t *testing.T) {
+	batch := errbatch.New(errbatch.WithWrapChain())
+	batch.Add(fmt.Errorf("read config: %w", fmt.Errorf("open file: %w", io.EOF)))
+	batch.AddCoded("code", errors.Join(errors.New("foo"), fmt.Errorf("bar: %w", io.EOF)))
+	batch.Add(errors.New("plain"))
+
+	const expected = "errbatch: total 3 error(s) in this batch:\n" +
+		"read config: open file: EOF\n" +
+		"    open file: EOF\n" +
+		"        EOF\n" +
+		"foo\nbar: EOF\n" +
+		"    foo\n" +
+		"    bar: EOF\n" +
+		"        EOF\n" +
+		"plain"
+	if actual := fmt.Sprintf("%+v", batch); actual != expected {
+		t.Errorf("Expected %q, got %q", expected, actual)
+	}
+
+	const message = "errbatch: total 3 error(s) in this batch: " +
+		"read config: open file: EOF; foo\nbar: EOF; plain"
+	if actual := batch.Error(); actual != message {
+		t.Errorf("Expected %q, got %q", message, actual)
+	}
+}
+
+func TestWithMaxChainDepth(t *testing.T) {
+	err := fmt.Errorf("a: %w", fmt.Errorf("b: %w", fmt.Errorf("c: %w", io.EOF)))
+	batch := errbatch.New(errbatch.WithMaxChainDepth(2))
+	batch.Add(err)
+	batch.Add(errors.New("foo"))
+
+	const expected = "errbatch: total 2 error(s) in this batch:\n" +
+		"a: b: c: EOF\n" +
+		"    b: c: EOF\n" +
+		"        c: EOF\n" +
+		"            ...\n" +
+		"foo"
+	if actual := fmt.Sprintf("%+v", batch); actual != expected {
+		t.Errorf("Expected %q, got %q", expected, actual)
+	}
+
+	var sb strings.Builder
+	n, writeErr := batch.WriteTo(&sb)
+	if writeErr != nil {
+		t.Fatal(writeErr)
+	}
+	if sb.String() != expected || n != int64(len(expected)) {
+		t.Errorf("Expected WriteTo to write %q, got %q (%d)", expected, sb.String(), n)
+	}
+}
+
+func TestWithWrapChainRedactor(t *testing.T) {
+	batch := errbatch.New(
+		errbatch.WithWrapChain(),
+		errbatch.WithRedactor(func(msg string) string {
+			return strings.ReplaceAll(msg, "hunter2", "***")
+		}),
+	)
+	batch.Add(fmt.Errorf("login: %w", errors.New("password=hunter2")))
+
+	expected := "errbatch: total 1 error(s) in this batch:\nlogin: password=***\n    password=***"
+	if actual := fmt.Sprintf("%+v", batch); actual != expected {
+		t.Errorf("Expected %q, got %q", expected, actual)
+	}
+}
@@ -0,0 +1,84 @@
+package errbatch
+
+import (
+	"sync"
+)
+
+var classifiers struct {
+	sync.RWMutex
+
+	m map[string]func(error) bool
+}
+
+// RegisterClassifier registers a process-wide classifier for error
+// categorization,
+// so categories (e.g. retryable, user error, infra) can be defined once,
+// and reported consistently by Classify and ClassCount of every batch.
+//
+// fn is called with each of the underlying errors of a batch,
+// and reports whether the error belongs to the category.
+// It must be safe to be called concurrently.
+//
+// Registering with the same name again replaces the previous classifier.
+// Nil fn unregisters the classifier with the name.
+//
+// It's usually called during initialization, e.g.:
+//
+//	func init() {
+//		errbatch.RegisterClassifier("retryable", func(err error) bool {
+//			return errors.Is(err, ErrUnavailable)
+//		})
+//	}
+func RegisterClassifier(name string, fn func(error) bool) {
+	classifiers.Lock()
+	defer classifiers.Unlock()
+	if fn == nil {
+		delete(classifiers.m, name)
+		return
+	}
+	if classifiers.m == nil {
+		classifiers.m = make(map[string]func(error) bool)
+	}
+	classifiers.m[name] = fn
+}
+
+// Classify returns the number of underlying errors in the batch belonging to
+// each of the classifiers registered by RegisterClassifier,
+// keyed by their names.
+//
+// Every registered classifier has a key in the result,
+// even if no errors belong to it.
+// An error can belong to multiple classifiers.
+func (eb *ErrBatch) Classify() map[string]int {
+	classifiers.RLock()
+	defer classifiers.RUnlock()
+	counts := make(map[string]int, len(classifiers.m))
+	for name, fn := range classifiers.m {
+		counts[name] = eb.classCount(fn)
+	}
+	return counts
+}
+
+// ClassCount returns the number of underlying errors in the batch belonging to
+// the classifier registered by RegisterClassifier with the name.
+//
+// It returns 0 when there's no classifier registered with the name.
+func (eb *ErrBatch) ClassCount(name string) int {
+	classifiers.RLock()
+	fn := classifiers.m[name]
+	classifiers.RUnlock()
+	if fn == nil {
+		return 0
+	}
+	return eb.classCount(fn)
+}
+
+func (eb *ErrBatch) classCount(fn func(error) bool) int {
+	var n int
+	for _, e := range eb.entries {
+		if fn(e.err) {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,57 @@
+package errbatch_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"testing"
+
+	"github.com/fishy/errbatch"
+)
+
+func TestClassify(t *testing.T) {
+	errUser := errors.New("user error")
+	errbatch.RegisterClassifier("user", func(err error) bool {
+		return errors.Is(err, errUser)
+	})
+	errbatch.RegisterClassifier("canceled", func(err error) bool {
+		return errors.Is(err, context.Canceled)
+	})
+	errbatch.RegisterClassifier("empty", func(error) bool {
+		return false
+	})
+	t.Cleanup(func() {
+		errbatch.RegisterClassifier("user", nil)
+		errbatch.RegisterClassifier("canceled", nil)
+		errbatch.RegisterClassifier("empty", nil)
+	})
+
+	var batch errbatch.ErrBatch
+	batch.Add(fmt.Errorf("bad input: %w", errUser))
+	batch.AddCoded("code", errUser)
+	batch.Add(context.Canceled)
+	batch.Add(errors.New("foo"))
+
+	expected := map[string]int{
+		"user":     2,
+		"canceled": 1,
+		"empty":    0,
+	}
+	if actual := batch.Classify(); !maps.Equal(actual, expected) {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+	if actual := batch.ClassCount("user"); actual != 2 {
+		t.Errorf("Expected 2, got %d", actual)
+	}
+	if actual := batch.ClassCount("unknown"); actual != 0 {
+		t.Errorf("Expected 0 for unknown classifier, got %d", actual)
+	}
+
+	t.Run("unregister", func(t *testing.T) {
+		errbatch.RegisterClassifier("empty", nil)
+		if _, ok := batch.Classify()["empty"]; ok {
+			t.Error("Expected unregistered classifier to be gone")
+		}
+	})
+}
@@ -0,0 +1,85 @@
+package errbatch
+
+import (
+	"errors"
+	"fmt"
+)
+
+// CodedError is an error carrying machine-readable code(s).
+//
+// Both *ErrBatch and the errors added via AddCoded implement it.
+type CodedError interface {
+	error
+
+	Codes() []string
+}
+
+// Make sure the types satisfy CodedError interface.
+var (
+	_ CodedError = (*codedError)(nil)
+	_ CodedError = (*ErrBatch)(nil)
+)
+
+type codedError struct {
+	code string
+	err  error
+}
+
+func (ce *codedError) Error() string {
+	return errorMessage(ce.err)
+}
+
+// Format forwards formatting to the underlying error,
+// so that verbose formatting (e.g. stack traces) is kept.
+func (ce *codedError) Format(s fmt.State, verb rune) {
+	fmt.Fprintf(s, fmt.FormatString(s, verb), ce.err)
+}
+
+func (ce *codedError) Unwrap() error {
+	return ce.err
+}
+
+func (ce *codedError) Codes() []string {
+	return []string{ce.code}
+}
+
+// AddCoded adds an error with a machine-readable code into the batch.
+//
+// The code does not change the error message.
+// It can be retrieved via Codes after the batch is compiled.
+//
+// If the error is also an ErrBatch,
+// the code will be attached to each of its underlying error(s).
+//
+// Nil error will be skipped.
+func (eb *ErrBatch) AddCoded(code string, err error) {
+	eb.add(err, func(e *entry) {
+		e.err = &codedError{code: code, err: e.err}
+	})
+}
+
+// Codes returns the codes of the underlying error(s) in the batch.
+//
+// Errors without codes are skipped,
+// so the result could be shorter than the number of errors in the batch.
+func (eb *ErrBatch) Codes() []string {
+	codes := make([]string, 0, len(eb.entries))
+	for _, e := range eb.entries {
+		codes = append(codes, Codes(e.err)...)
+	}
+	return codes
+}
+
+// Codes returns the code(s) carried by err.
+//
+// It's a shorthand for errors.As with CodedError,
+// and returns nil when err does not carry any code.
+// It's useful to be called on the error returned by Compile,
+// which is either nil, a single error, or the batch itself.
+func Codes(err error) []string {
+	var ce CodedError
+	if errors.As(err, &ce) {
+		return ce.Codes()
+	}
+	return nil
+}
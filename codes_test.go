@@ -0,0 +1,49 @@
+package errbatch_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/fishy/errbatch"
+)
+
+func TestCodes(t *testing.T) {
+	var batch errbatch.ErrBatch
+	batch.AddCoded("nil", nil)
+	if len(batch.GetErrors()) != 0 {
+		t.Error("Nil errors should be skipped.")
+	}
+	if codes := errbatch.Codes(batch.Compile()); codes != nil {
+		t.Errorf("Expected nil codes for empty batch, got %#v", codes)
+	}
+
+	err0 := errors.New("foo")
+	batch.AddCoded("E_FOO", err0)
+	err := batch.Compile()
+	if err.Error() != "foo" {
+		t.Errorf("Expected message %q, got %q", "foo", err.Error())
+	}
+	if !errors.Is(err, err0) {
+		t.Errorf("Expected %v to wrap %v", err, err0)
+	}
+	expected := []string{"E_FOO"}
+	if codes := errbatch.Codes(err); !reflect.DeepEqual(codes, expected) {
+		t.Errorf("Expected codes %#v, got %#v", expected, codes)
+	}
+
+	batch.Add(errors.New("bar"))
+	var another errbatch.ErrBatch
+	another.Add(errors.New("foobar"))
+	another.Add(errors.New("barfoo"))
+	batch.AddCoded("E_NESTED", &another)
+	err = batch.Compile()
+	expected = []string{"E_FOO", "E_NESTED", "E_NESTED"}
+	if codes := errbatch.Codes(err); !reflect.DeepEqual(codes, expected) {
+		t.Errorf("Expected codes %#v, got %#v", expected, codes)
+	}
+	expectedMsg := "errbatch: total 4 error(s) in this batch: foo; bar; foobar; barfoo"
+	if err.Error() != expectedMsg {
+		t.Errorf("Expected message %q, got %q", expectedMsg, err.Error())
+	}
+}
@@ -0,0 +1,69 @@
+package errbatch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrTruncated is added into the batches returned by CollectChannels and
+// CollectUntil when the collection is stopped by the context,
+// wrapped together with the cause of the context (see context.Cause).
+var ErrTruncated = errors.New("errbatch: collection truncated")
+
+// CollectChannels collects the errors from chans into a batch,
+// until all of chans are closed or ctx is done,
+// which is a common need to fan in the error channels of multiple stages of
+// channel-based pipelines.
+//
+// The errors are in the order they were received.
+// Nil errors are skipped.
+// If ctx is done before all of chans are closed,
+// an error matching both ErrTruncated and the cause of ctx via errors.Is is
+// also added into the batch, as the batch might be incomplete.
+//
+// See ErrBatch.Compile for details of the returned error.
+func CollectChannels(ctx context.Context, chans ...<-chan error) error {
+	var batch ConcurrentErrBatch
+	var wg sync.WaitGroup
+	var closed atomic.Int64
+	for _, ch := range chans {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case err, ok := <-ch:
+					if !ok {
+						closed.Add(1)
+						return
+					}
+					batch.Add(err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if closed.Load() < int64(len(chans)) {
+		batch.Add(fmt.Errorf("%w: %w", ErrTruncated, context.Cause(ctx)))
+	}
+	return batch.Compile()
+}
+
+// CollectUntil collects the errors from ch into a batch,
+// until ch is closed or ctx is done,
+// and compiles whatever was received,
+// which is important for bounded paths like shutdowns.
+//
+// If ctx is done before ch is closed,
+// an error matching both ErrTruncated and the cause of ctx via errors.Is is
+// also added into the batch.
+//
+// It's the same as CollectChannels with a single channel.
+func CollectUntil(ctx context.Context, ch <-chan error) error {
+	return CollectChannels(ctx, ch)
+}
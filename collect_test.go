@@ -0,0 +1,93 @@
+package errbatch_test
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/fishy/errbatch"
+)
+
+func TestCollectChannels(t *testing.T) {
+	t.Run("closed", func(t *testing.T) {
+		ch1 := make(chan error, 2)
+		ch2 := make(chan error, 2)
+		ch1 <- errors.New("foo")
+		ch1 <- nil
+		ch2 <- errors.New("bar")
+		close(ch1)
+		close(ch2)
+
+		var batch *errbatch.ErrBatch
+		if err := errbatch.CollectChannels(context.Background(), ch1, ch2); !errors.As(err, &batch) {
+			t.Fatalf("Expected a batch, got %#v", err)
+		}
+		if actual := len(batch.GetErrors()); actual != 2 {
+			t.Errorf("Expected 2 errors, got %d", actual)
+		}
+	})
+
+	t.Run("none", func(t *testing.T) {
+		if err := errbatch.CollectChannels(context.Background()); err != nil {
+			t.Errorf("Expected nil, got %#v", err)
+		}
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		ch := make(chan error, 1)
+		errFoo := errors.New("foo")
+		ch <- errFoo
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+		go func() {
+			done <- errbatch.CollectChannels(ctx, ch)
+		}()
+		// Make sure errFoo is received before canceling.
+		for len(ch) > 0 {
+			runtime.Gosched()
+		}
+		cancel()
+
+		var batch *errbatch.ErrBatch
+		if err := <-done; !errors.As(err, &batch) {
+			t.Fatalf("Expected a batch, got %#v", err)
+		}
+		errs := batch.GetErrors()
+		if len(errs) != 2 || errs[0] != errFoo {
+			t.Fatalf("Expected %v and truncated error, got %v", errFoo, errs)
+		}
+		if !errors.Is(errs[1], errbatch.ErrTruncated) || !errors.Is(errs[1], context.Canceled) {
+			t.Errorf("Expected truncated by context canceled, got %v", errs[1])
+		}
+	})
+}
+
+func TestCollectUntil(t *testing.T) {
+	ch := make(chan error, 1)
+	errFoo := errors.New("foo")
+	ch <- errFoo
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	var batch *errbatch.ErrBatch
+	if err := errbatch.CollectUntil(ctx, ch); !errors.As(err, &batch) {
+		t.Fatalf("Expected a batch, got %#v", err)
+	}
+	errs := batch.GetErrors()
+	if len(errs) != 2 || errs[0] != errFoo {
+		t.Fatalf("Expected %v and truncated error, got %v", errFoo, errs)
+	}
+	if !errors.Is(errs[1], errbatch.ErrTruncated) || !errors.Is(errs[1], context.DeadlineExceeded) {
+		t.Errorf("Expected truncated by deadline, got %v", errs[1])
+	}
+
+	t.Run("closed", func(t *testing.T) {
+		ch := make(chan error)
+		close(ch)
+		if err := errbatch.CollectUntil(context.Background(), ch); err != nil {
+			t.Errorf("Expected nil, got %#v", err)
+		}
+	})
+}
@@ -0,0 +1,173 @@
+package errbatch
+
+import (
+	"encoding"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+
+	"golang.org/x/xerrors"
+)
+
+// Make sure CompiledBatch satisfies the interfaces.
+var (
+	_ error                    = CompiledBatch{}
+	_ fmt.Formatter            = CompiledBatch{}
+	_ xerrors.Formatter        = CompiledBatch{}
+	_ json.Marshaler           = CompiledBatch{}
+	_ xml.Marshaler            = CompiledBatch{}
+	_ encoding.BinaryMarshaler = CompiledBatch{}
+)
+
+// CompiledBatch is an immutable snapshot of a batch containing two or more
+// errors, returned by CompileValue.
+//
+// Unlike the *ErrBatch returned by Compile,
+// it's a value type that shares nothing with the batch it's compiled from,
+// so adding more errors to (or clearing) the batch afterwards does not
+// change it,
+// and it can never be a non-nil error wrapping an empty batch.
+//
+// It formats and serializes (e.g. MarshalJSON) the same as ErrBatch,
+// including the traceparent and the Dropped and Skipped counts,
+// and errors.As with **ErrBatch target on it returns a copy of the batch.
+//
+// CompiledBatch values are comparable:
+// copies of the same CompileValue result are equal.
+type CompiledBatch struct {
+	// compiled is never modified after CompileValue.
+	compiled *ErrBatch
+}
+
+// CompileValue compiles the batch into an immutable value.
+//
+// If the batch contains zero errors, it will return nil.
+//
+// If the batch contains exactly one error,
+// that underlying error will be returned,
+// unless WithNoSingleUnwrap is used.
+//
+// Otherwise, a CompiledBatch of the errors will be returned.
+//
+// Like Compile, it delivers the result to the Notifier set by WithNotifier.
+func (eb *ErrBatch) CompileValue() error {
+	countCompiled()
+	switch {
+	case len(eb.entries) == 0:
+		return eb.notify(nil)
+	case len(eb.entries) == 1 && !eb.getOptions().noSingleUnwrap:
+		return eb.notify(eb.entries[0].err)
+	default:
+		compiled := new(ErrBatch)
+		compiled.setBatch(eb)
+		return eb.notify(CompiledBatch{compiled: compiled})
+	}
+}
+
+// batch returns the compiled batch of cb.
+//
+// The returned batch must not be modified.
+func (cb CompiledBatch) batch() *ErrBatch {
+	if cb.compiled == nil {
+		return &ErrBatch{}
+	}
+	return cb.compiled
+}
+
+func (cb CompiledBatch) Error() string {
+	return cb.batch().Error()
+}
+
+// Format implements fmt.Formatter, the same as ErrBatch.
+func (cb CompiledBatch) Format(s fmt.State, verb rune) {
+	cb.batch().Format(s, verb)
+}
+
+// FormatError implements xerrors.Formatter, the same as ErrBatch.
+func (cb CompiledBatch) FormatError(p xerrors.Printer) error {
+	return cb.batch().FormatError(p)
+}
+
+// Len returns the number of errors in the batch.
+func (cb CompiledBatch) Len() int {
+	return len(cb.batch().entries)
+}
+
+// Traceparent returns the traceparent of the batch, see ErrBatch.Traceparent.
+func (cb CompiledBatch) Traceparent() string {
+	return cb.batch().Traceparent()
+}
+
+// Dropped returns the number of errors dropped by the batch,
+// see ErrBatch.Dropped.
+func (cb CompiledBatch) Dropped() int {
+	return cb.batch().Dropped()
+}
+
+// Skipped returns the number of errors skipped by the batch,
+// see ErrBatch.Skipped.
+func (cb CompiledBatch) Skipped() int {
+	return cb.batch().Skipped()
+}
+
+// MarshalJSON implements json.Marshaler, the same as ErrBatch.
+func (cb CompiledBatch) MarshalJSON() ([]byte, error) {
+	return cb.batch().MarshalJSON()
+}
+
+// MarshalXML implements xml.Marshaler, the same as ErrBatch.
+func (cb CompiledBatch) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return cb.batch().MarshalXML(e, start)
+}
+
+// MarshalYAML implements the Marshaler interfaces of gopkg.in/yaml,
+// the same as ErrBatch.
+func (cb CompiledBatch) MarshalYAML() (interface{}, error) {
+	return cb.batch().MarshalYAML()
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler, the same as ErrBatch.
+func (cb CompiledBatch) MarshalBinary() ([]byte, error) {
+	return cb.batch().MarshalBinary()
+}
+
+// MarshalCBOR encodes the batch the same as ErrBatch.MarshalCBOR.
+func (cb CompiledBatch) MarshalCBOR() ([]byte, error) {
+	return cb.batch().MarshalCBOR()
+}
+
+// MarshalMsgpack encodes the batch the same as ErrBatch.MarshalMsgpack.
+func (cb CompiledBatch) MarshalMsgpack() ([]byte, error) {
+	return cb.batch().MarshalMsgpack()
+}
+
+// GetErrors returns a copy of the underlying errors.
+func (cb CompiledBatch) GetErrors() []error {
+	return cb.batch().GetErrors()
+}
+
+// Unwrap returns all the errors in the batch,
+// so that errors.Is and errors.As can check each of them.
+func (cb CompiledBatch) Unwrap() []error {
+	return cb.GetErrors()
+}
+
+// As implements helper interface for errors.As,
+// supporting the same targets as ErrBatch.
+func (cb CompiledBatch) As(v any) bool {
+	return cb.batch().As(v)
+}
+
+// Is implements helper interface for errors.Is.
+//
+// It reports true when target is an *ErrBatch or CompiledBatch,
+// matching the errors pairwise the same as ErrBatch.Is.
+func (cb CompiledBatch) Is(target error) bool {
+	switch t := target.(type) {
+	case CompiledBatch:
+		return entriesMatch(cb.batch().entries, t.batch().entries)
+	case *ErrBatch:
+		return t != nil && entriesMatch(cb.batch().entries, t.entries)
+	}
+	return false
+}
@@ -0,0 +1,106 @@
+package errbatch_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/fishy/errbatch"
+)
+
+func TestCompileValue(t *testing.T) {
+	var batch errbatch.ErrBatch
+	if err := batch.CompileValue(); err != nil {
+		t.Errorf("Expected nil for empty batch, got %#v", err)
+	}
+
+	err0 := errors.New("foo")
+	batch.Add(err0)
+	if err := batch.CompileValue(); err != err0 {
+		t.Errorf("Expected %#v, got %#v", err0, err)
+	}
+
+	batch.Add(io.EOF)
+	err := batch.CompileValue()
+	expectedStr := batch.Error()
+	batch.Add(errors.New("bar"))
+	batch.Clear()
+
+	var cb errbatch.CompiledBatch
+	if !errors.As(err, &cb) {
+		t.Fatalf("Expected a CompiledBatch, got %#v", err)
+	}
+	if cb.Len() != 2 {
+		t.Errorf("Expected the compiled batch to be unchanged, got %d errors", cb.Len())
+	}
+	if err.Error() != expectedStr {
+		t.Errorf("Expected %q, got %q", expectedStr, err.Error())
+	}
+	if actual := fmt.Sprintf("%+v", err); actual != "errbatch: total 2 error(s) in this batch:\nfoo\nEOF" {
+		t.Errorf("Unexpected verbose format %q", actual)
+	}
+
+	if !errors.Is(err, io.EOF) {
+		t.Errorf("Expected errors.Is(%v, io.EOF) to be true", err)
+	}
+	var eb *errbatch.ErrBatch
+	if !errors.As(err, &eb) {
+		t.Fatalf("Expected errors.As(%v, **ErrBatch) to be true", err)
+	}
+	expected := []error{err0, io.EOF}
+	if actual := eb.GetErrors(); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %#v, got %#v", expected, actual)
+	}
+	if !errors.Is(err, eb) {
+		t.Errorf("Expected errors.Is(%v, %v) to be true", err, eb)
+	}
+	if !errors.Is(err, eb.CompileValue()) {
+		t.Errorf("Expected errors.Is(%v, %v) to be true", err, eb.CompileValue())
+	}
+}
+
+func TestCompileValueState(t *testing.T) {
+	const traceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	batch := errbatch.New(errbatch.WithIgnore(io.ErrUnexpectedEOF))
+	if err := batch.SetTraceparent(traceparent); err != nil {
+		t.Fatal(err)
+	}
+	batch.Add(errors.New("foo"))
+	batch.Add(io.EOF)
+	batch.Add(io.ErrUnexpectedEOF)
+
+	err := batch.CompileValue()
+	other := err
+	if err != other {
+		t.Errorf("Expected %#v to be equal to itself", err)
+	}
+	if err == batch.CompileValue() {
+		t.Errorf("Expected different CompileValue results to be unequal")
+	}
+
+	cb, ok := err.(errbatch.CompiledBatch)
+	if !ok {
+		t.Fatalf("Expected a CompiledBatch, got %#v", err)
+	}
+	if cb.Traceparent() != traceparent {
+		t.Errorf("Expected traceparent %q, got %q", traceparent, cb.Traceparent())
+	}
+	if cb.Skipped() != 1 {
+		t.Errorf("Expected 1 skipped error, got %d", cb.Skipped())
+	}
+
+	expected, marshalErr := batch.MarshalJSON()
+	if marshalErr != nil {
+		t.Fatal(marshalErr)
+	}
+	actual, marshalErr := json.Marshal(err)
+	if marshalErr != nil {
+		t.Fatal(marshalErr)
+	}
+	if string(actual) != string(expected) {
+		t.Errorf("Expected %s, got %s", expected, actual)
+	}
+}
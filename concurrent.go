@@ -0,0 +1,289 @@
+package errbatch
+
+import (
+	"math/bits"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// Make sure *ConcurrentErrBatch satisfies error interface.
+var _ error = (*ConcurrentErrBatch)(nil)
+
+// ConcurrentErrBatch is a thread-safe batch.
+//
+// Add is lock-free:
+// it reserves slot(s) with a single atomic operation and stores the error(s)
+// into an atomically grown list of segments,
+// so concurrent Add calls do not contend on a mutex.
+//
+// Readers (Len, Error, GetErrors, Snapshot, Compile) can run concurrently with
+// each other and with Add,
+// and always see a consistent snapshot of the batch,
+// containing all the errors added by Add calls returned before the read.
+// It's useful for monitoring endpoints inspecting an in-progress batch
+// without stopping the collection.
+//
+// When the batch is created with WithDedup or a retention policy
+// (WithTopN, WithRingBuffer or WithUniqueLRU),
+// including via SetDefaults,
+// Add is serialized with a mutex instead,
+// so that duplicates are skipped and errors are evicted as they are added,
+// and the memory used by the batch stays bounded.
+//
+// The zero value of ConcurrentErrBatch is valid (with no errors) and ready to
+// use.
+// ConcurrentErrBatch must not be copied after first use.
+type ConcurrentErrBatch struct {
+	opts *options
+	list atomic.Pointer[segmentList]
+
+	// retained holds the errors added with WithDedup or a retention policy,
+	// guarded by mu.
+	mu       sync.Mutex
+	retained ErrBatch
+
+	// subscribers registered by Subscribe, copied on write under subsMu.
+	subsMu sync.Mutex
+	subs   atomic.Pointer[[]*subscriber]
+}
+
+// NewConcurrent creates a new ConcurrentErrBatch with the given options.
+func NewConcurrent(opts ...Option) *ConcurrentErrBatch {
+	return &ConcurrentErrBatch{
+		opts: New(opts...).opts,
+	}
+}
+
+func (cb *ConcurrentErrBatch) getList() *segmentList {
+	if l := cb.list.Load(); l != nil {
+		return l
+	}
+	cb.list.CompareAndSwap(nil, new(segmentList))
+	return cb.list.Load()
+}
+
+func (cb *ConcurrentErrBatch) getOptions() *options {
+	if cb.opts != nil {
+		return cb.opts
+	}
+	return getDefaultOptions()
+}
+
+// Add adds an error into the batch.
+//
+// See ErrBatch.Add for details.
+func (cb *ConcurrentErrBatch) Add(err error) {
+	cb.add(err, nil)
+}
+
+// AddNamed adds an error keyed by name into the batch.
+//
+// See ErrBatch.AddNamed for details.
+func (cb *ConcurrentErrBatch) AddNamed(name string, err error) {
+	cb.add(err, func(e *entry) {
+		e.err = &namedError{name: name, err: e.err}
+	})
+}
+
+// Go increments wg, calls fn in a new goroutine,
+// adds the error it returns into the batch, and then marks wg as done,
+// for callers already managing their own sync.WaitGroup:
+//
+//	var wg sync.WaitGroup
+//	var batch errbatch.ConcurrentErrBatch
+//	for _, work := range works {
+//		batch.Go(&wg, work)
+//	}
+//	wg.Wait()
+//	return batch.Compile()
+//
+// See Group for a version managing the sync.WaitGroup itself.
+func (cb *ConcurrentErrBatch) Go(wg *sync.WaitGroup, fn func() error) {
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		cb.Add(fn())
+	}()
+}
+
+// add is the implementation of Add.
+//
+// See ErrBatch.add for details.
+// It must be called directly from the exported method (e.g. Add).
+func (cb *ConcurrentErrBatch) add(err error, modify func(*entry)) {
+	if err == nil {
+		return
+	}
+
+	opts := cb.getOptions()
+	site := opts.callSite(1)
+	entries, ok := opts.flatten(err, site.newEntry)
+	if ok {
+		var skipped int
+		entries, skipped = opts.filter(entries)
+		cb.getList().skipped.Add(int64(skipped))
+	} else {
+		if opts.ignored(err) {
+			cb.getList().skipped.Add(1)
+			countDropped(1)
+			return
+		}
+		entries = []entry{site.newEntry(err)}
+	}
+	if modify != nil {
+		for i := range entries {
+			modify(&entries[i])
+		}
+	}
+	n := len(entries)
+	if opts.retains() {
+		cb.mu.Lock()
+		cb.retained.opts = opts
+		n = cb.retained.appendEntries(entries...)
+		cb.mu.Unlock()
+	} else {
+		cb.getList().append(entries...)
+	}
+	countAdded(n)
+	cb.publish(entries)
+}
+
+// Clear clears the batch.
+//
+// Add calls running concurrently with Clear could be either kept or cleared.
+func (cb *ConcurrentErrBatch) Clear() {
+	cb.list.Store(new(segmentList))
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.retained.Clear()
+}
+
+// Len returns the number of errors in the batch.
+func (cb *ConcurrentErrBatch) Len() int {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	return int(cb.getList().size.Load()) + len(cb.retained.entries)
+}
+
+// Error satisfies the error interface.
+//
+// See ErrBatch.Error for details.
+func (cb *ConcurrentErrBatch) Error() string {
+	return cb.Snapshot().Error()
+}
+
+// GetErrors returns a copy of the underlying error(s).
+func (cb *ConcurrentErrBatch) GetErrors() []error {
+	return cb.Snapshot().GetErrors()
+}
+
+// Snapshot returns a copy of the batch as an ErrBatch.
+//
+// Further changes to cb will not be reflected in the returned batch.
+func (cb *ConcurrentErrBatch) Snapshot() *ErrBatch {
+	list := cb.getList()
+	batch := &ErrBatch{
+		opts:    cb.opts,
+		skipped: int(list.skipped.Load()),
+	}
+	cb.mu.Lock()
+	batch.entries = cb.retained.copyEntries()
+	batch.dropped = cb.retained.dropped
+	cb.mu.Unlock()
+	batch.appendEntries(list.snapshot()...)
+	batch.sortBySeq()
+	return batch
+}
+
+// As implements helper interface for errors.As.
+//
+// It works the same as ErrBatch.As on a snapshot of the batch,
+// so that adding a ConcurrentErrBatch into an ErrBatch adds its underlying
+// error(s) instead.
+func (cb *ConcurrentErrBatch) As(v interface{}) bool {
+	return cb.Snapshot().As(v)
+}
+
+// Compile compiles a snapshot of the batch.
+//
+// See ErrBatch.Compile for details.
+// When there are multiple errors, the returned error is a snapshot,
+// so further changes to cb will not be reflected in it.
+func (cb *ConcurrentErrBatch) Compile() error {
+	return cb.Snapshot().Compile()
+}
+
+const (
+	// The size of the first segment.
+	// Each following segment doubles the size of the previous one.
+	segmentBase = 16
+
+	// Enough segments to hold any int64 index.
+	maxSegments = 64 - 4 // log2(segmentBase)
+)
+
+// segmentList is an append-only list of entries that can be grown
+// atomically.
+type segmentList struct {
+	// The number of reserved slots.
+	size atomic.Int64
+
+	// The number of errors skipped by WithIgnore.
+	skipped atomic.Int64
+
+	segments [maxSegments]atomic.Pointer[segment]
+}
+
+type segment struct {
+	slots []atomic.Pointer[entry]
+}
+
+// locate returns the segment index and the offset inside that segment for
+// the i-th slot.
+func locate(i int64) (k int, offset int64) {
+	k = bits.Len64(uint64(i/segmentBase+1)) - 1
+	offset = i - segmentBase*(1<<k-1)
+	return k, offset
+}
+
+func (l *segmentList) slot(i int64) *atomic.Pointer[entry] {
+	k, offset := locate(i)
+	seg := l.segments[k].Load()
+	if seg == nil {
+		seg = &segment{
+			slots: make([]atomic.Pointer[entry], segmentBase<<k),
+		}
+		if !l.segments[k].CompareAndSwap(nil, seg) {
+			seg = l.segments[k].Load()
+		}
+	}
+	return &seg.slots[offset]
+}
+
+func (l *segmentList) append(entries ...entry) {
+	n := int64(len(entries))
+	start := l.size.Add(n) - n
+	for i := range entries {
+		l.slot(start + int64(i)).Store(&entries[i])
+	}
+}
+
+// snapshot returns all the entries in reserved slots.
+//
+// If a slot is reserved but not stored yet by a concurrent append,
+// it waits for the append to finish.
+func (l *segmentList) snapshot() []entry {
+	n := l.size.Load()
+	entries := make([]entry, 0, n)
+	for i := int64(0); i < n; i++ {
+		slot := l.slot(i)
+		e := slot.Load()
+		for e == nil {
+			runtime.Gosched()
+			e = slot.Load()
+		}
+		entries = append(entries, *e)
+	}
+	return entries
+}
@@ -0,0 +1,32 @@
+package errbatch
+
+import (
+	"testing"
+)
+
+func TestLocate(t *testing.T) {
+	for _, c := range []struct {
+		i      int64
+		k      int
+		offset int64
+	}{
+		{i: 0, k: 0, offset: 0},
+		{i: segmentBase - 1, k: 0, offset: segmentBase - 1},
+		{i: segmentBase, k: 1, offset: 0},
+		{i: segmentBase*3 - 1, k: 1, offset: segmentBase*2 - 1},
+		{i: segmentBase * 3, k: 2, offset: 0},
+		{i: segmentBase * 7, k: 3, offset: 0},
+	} {
+		k, offset := locate(c.i)
+		if k != c.k || offset != c.offset {
+			t.Errorf(
+				"locate(%d) expected (%d, %d), got (%d, %d)",
+				c.i,
+				c.k,
+				c.offset,
+				k,
+				offset,
+			)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package errbatch_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/fishy/errbatch"
+)
+
+func TestConcurrentErrBatch(t *testing.T) {
+	var batch errbatch.ConcurrentErrBatch
+	if err := batch.Compile(); err != nil {
+		t.Errorf("An empty batch should be compiled to nil, got: %#v", err)
+	}
+
+	err0 := errors.New("foo")
+	batch.Add(nil)
+	batch.Add(err0)
+	if err := batch.Compile(); err != err0 {
+		t.Errorf("A single error batch should be compiled to %#v, got %#v", err0, err)
+	}
+
+	batch.Add(errors.New("bar"))
+	err := batch.Compile()
+	batch.Add(errors.New("foobar"))
+	expected := "errbatch: total 2 error(s) in this batch: foo; bar"
+	if err.Error() != expected {
+		t.Errorf("Expected compiled snapshot %q, got %q", expected, err.Error())
+	}
+	expected = "errbatch: total 3 error(s) in this batch: foo; bar; foobar"
+	if batch.Error() != expected {
+		t.Errorf("Expected %q, got %q", expected, batch.Error())
+	}
+
+	batch.Clear()
+	if batch.Len() != 0 {
+		t.Errorf("A cleared batch should contain zero errors, got %d", batch.Len())
+	}
+}
+
+func TestConcurrentErrBatchReaders(t *testing.T) {
+	const n = 100
+	batch := errbatch.NewConcurrent(errbatch.WithCaller())
+	var wg sync.WaitGroup
+	wg.Add(n * 2)
+	for i := 0; i < n; i++ {
+		go func(i int) {
+			defer wg.Done()
+			batch.Add(fmt.Errorf("error %d", i))
+		}(i)
+		go func() {
+			defer wg.Done()
+			snapshot := batch.Snapshot()
+			if len(snapshot.GetErrors()) > n {
+				t.Errorf("Too many errors in snapshot: %v", snapshot)
+			}
+			_ = batch.Error()
+			_ = batch.Len()
+		}()
+	}
+	wg.Wait()
+
+	if l := batch.Len(); l != n {
+		t.Errorf("Expected %d errors, got %d", n, l)
+	}
+	verbose := fmt.Sprintf("%+v", batch.Snapshot())
+	if !strings.Contains(verbose, "concurrent_test.go:") {
+		t.Errorf("Expected call sites in verbose output, got %q", verbose)
+	}
+}
+
+func TestConcurrentErrBatchAs(t *testing.T) {
+	var batch errbatch.ConcurrentErrBatch
+	batch.Add(errors.New("foo"))
+	batch.Add(errors.New("bar"))
+
+	var another errbatch.ErrBatch
+	another.Add(&batch)
+	if errs := another.GetErrors(); len(errs) != 2 {
+		t.Errorf("Expected the underlying 2 errors to be added, got %v", errs)
+	}
+}
+
+func BenchmarkConcurrentErrBatch(b *testing.B) {
+	err := errors.New("foo")
+	b.Run("lock-free", func(b *testing.B) {
+		var batch errbatch.ConcurrentErrBatch
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				batch.Add(err)
+			}
+		})
+	})
+	b.Run("mutex", func(b *testing.B) {
+		var mu sync.Mutex
+		var batch errbatch.ErrBatch
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				mu.Lock()
+				batch.Add(err)
+				mu.Unlock()
+			}
+		})
+	})
+}
+
+func TestConcurrentGo(t *testing.T) {
+	var wg sync.WaitGroup
+	var batch errbatch.ConcurrentErrBatch
+	for i := range 10 {
+		batch.Go(&wg, func() error {
+			if i%2 == 0 {
+				return nil
+			}
+			return fmt.Errorf("error %d", i)
+		})
+	}
+	wg.Wait()
+	if actual := batch.Len(); actual != 5 {
+		t.Errorf("Expected 5 errors, got %d", actual)
+	}
+}
+
+func TestConcurrentErrBatchRetention(t *testing.T) {
+	for _, c := range []struct {
+		label    string
+		opt      errbatch.Option
+		expected int
+	}{
+		{
+			label:    "ring-buffer",
+			opt:      errbatch.WithRingBuffer(10),
+			expected: 10,
+		},
+		{
+			label: "top-n",
+			opt: errbatch.WithTopN(10, func(error) int {
+				return 0
+			}),
+			expected: 10,
+		},
+		{
+			label:    "unique-lru",
+			opt:      errbatch.WithUniqueLRU(10, nil),
+			expected: 10,
+		},
+		{
+			label:    "dedup",
+			opt:      errbatch.WithDedup(errbatch.DedupByMessage),
+			expected: 20,
+		},
+	} {
+		t.Run(c.label, func(t *testing.T) {
+			batch := errbatch.NewConcurrent(c.opt)
+			var wg sync.WaitGroup
+			for i := range 1000 {
+				batch.Go(&wg, func() error {
+					return fmt.Errorf("error %d", i%20)
+				})
+			}
+			wg.Wait()
+
+			if actual := batch.Len(); actual != c.expected {
+				t.Errorf("Expected Len %d, got %d", c.expected, actual)
+			}
+			if actual := len(batch.GetErrors()); actual != c.expected {
+				t.Errorf("Expected %d errors, got %d", c.expected, actual)
+			}
+
+			batch.Clear()
+			if actual := batch.Len(); actual != 0 {
+				t.Errorf("Expected Len 0 after Clear, got %d", actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,224 @@
+package errbatch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"time"
+)
+
+type contextKeyType struct{}
+
+var contextKey contextKeyType
+
+// NewContext returns a copy of ctx carrying batch.
+//
+// The batch can be retrieved via FromContext,
+// and errors can be added into it via AddToContext.
+func NewContext(ctx context.Context, batch *ConcurrentErrBatch) context.Context {
+	return context.WithValue(ctx, contextKey, batch)
+}
+
+// FromContext returns the batch carried by ctx,
+// or nil if ctx does not carry one.
+func FromContext(ctx context.Context) *ConcurrentErrBatch {
+	batch, _ := ctx.Value(contextKey).(*ConcurrentErrBatch)
+	return batch
+}
+
+// AddToContext adds err into the batch carried by ctx,
+// the same as ConcurrentErrBatch.AddContext.
+//
+// It returns false if ctx does not carry a batch,
+// in which case err is discarded.
+func AddToContext(ctx context.Context, err error) bool {
+	batch := FromContext(ctx)
+	if batch == nil {
+		return false
+	}
+	batch.add(annotateContextError(ctx, err), batch.getOptions().tagContext(ctx))
+	return true
+}
+
+// WithTraceExtractor sets the function to extract the trace ID (or request
+// ID) from the context passed to the context-aware add functions
+// (AddContext and AddToContext).
+//
+// Each error added with the context is tagged with the extracted ID,
+// which is printed by the verbose forms (e.g. %+v) as "[trace ID]",
+// and returned by TraceIDs,
+// so the errors can be joined back to distributed traces.
+// Empty IDs are ignored.
+func WithTraceExtractor(extract func(ctx context.Context) string) Option {
+	return func(o *options) {
+		o.traceExtractor = extract
+	}
+}
+
+// tagContext returns the modifier to tag entries with the trace ID extracted
+// from ctx, or nil if there's nothing to tag.
+func (opts *options) tagContext(ctx context.Context) func(*entry) {
+	if opts.traceExtractor == nil {
+		return nil
+	}
+	id := opts.traceExtractor(ctx)
+	if id == "" {
+		return nil
+	}
+	return func(e *entry) {
+		if e.getMeta().traceID == "" {
+			e.editMeta().traceID = id
+		}
+	}
+}
+
+// TraceIDs returns the trace IDs the underlying error(s) were tagged with
+// (see WithTraceExtractor).
+//
+// The returned slice has the same length and order as GetErrors.
+// For errors without trace IDs, the corresponding element is empty.
+func (eb *ErrBatch) TraceIDs() []string {
+	ids := make([]string, len(eb.entries))
+	for i, e := range eb.entries {
+		ids[i] = e.getMeta().traceID
+	}
+	return ids
+}
+
+// contextError annotates a context error with details of the context.
+type contextError struct {
+	err      error
+	deadline time.Time
+	cause    error
+}
+
+func (ce *contextError) Error() string {
+	var details []string
+	if !ce.deadline.IsZero() {
+		details = append(details, "deadline: "+ce.deadline.Format(time.RFC3339Nano))
+	}
+	if ce.cause != nil {
+		details = append(details, "cause: "+ce.cause.Error())
+	}
+	return ce.err.Error() + " (" + strings.Join(details, ", ") + ")"
+}
+
+// Format keeps verbose formatting (e.g. stack traces) of the underlying error.
+func (ce *contextError) Format(s fmt.State, verb rune) {
+	if verb == 'v' && s.Flag('+') {
+		fmt.Fprintf(s, "%+v", ce.err)
+		io.WriteString(s, strings.TrimPrefix(ce.Error(), ce.err.Error()))
+		return
+	}
+	fmt.Fprintf(s, fmt.FormatString(s, verb), ce.Error())
+}
+
+// Unwrap returns the original error.
+//
+// It's a single error instead of both the original error and the cause,
+// so WithRecursiveFlatten does not split the annotated error.
+func (ce *contextError) Unwrap() error {
+	return ce.err
+}
+
+// Is implements helper interface for errors.Is,
+// so the cause can also be matched.
+func (ce *contextError) Is(target error) bool {
+	return ce.cause != nil && errors.Is(ce.cause, target)
+}
+
+// As implements helper interface for errors.As,
+// so the cause can also be matched.
+//
+// Only targets of error types are matched against the cause,
+// so a cause that's also a batch does not get the annotated error flattened.
+func (ce *contextError) As(target any) bool {
+	if ce.cause == nil {
+		return false
+	}
+	typ := reflect.TypeOf(target)
+	if typ == nil || typ.Kind() != reflect.Pointer {
+		return false
+	}
+	if elem := typ.Elem(); !elem.Implements(errorType) || elem.Implements(batchSetterType) {
+		return false
+	}
+	return errors.As(ce.cause, target)
+}
+
+var errorType = reflect.TypeFor[error]()
+
+// AddContext adds an error into the batch the same as Add,
+// but annotates context errors with details from ctx.
+//
+// When err matches (errors.Is) context.Canceled or
+// context.DeadlineExceeded and ctx is done,
+// the deadline of ctx (if any) and context.Cause(ctx) (if it's different
+// from ctx.Err()) are appended to the error message, e.g.
+// "context deadline exceeded (deadline: 2006-01-02T15:04:05Z, cause: upstream too slow)".
+// The cause can also be matched by errors.Is and errors.As.
+//
+// Other errors are added unchanged.
+//
+// When the batch is created with WithTraceExtractor,
+// the error is also tagged with the trace ID extracted from ctx.
+func (eb *ErrBatch) AddContext(ctx context.Context, err error) {
+	eb.add(annotateContextError(ctx, err), eb.getOptions().tagContext(ctx))
+}
+
+// AddContext adds an error into the batch the same as Add,
+// with details from ctx.
+//
+// See ErrBatch.AddContext for details.
+func (cb *ConcurrentErrBatch) AddContext(ctx context.Context, err error) {
+	cb.add(annotateContextError(ctx, err), cb.getOptions().tagContext(ctx))
+}
+
+func annotateContextError(ctx context.Context, err error) error {
+	if err == nil || ctx.Err() == nil {
+		return err
+	}
+	if !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+	ce := &contextError{err: err}
+	ce.deadline, _ = ctx.Deadline()
+	if cause := context.Cause(ctx); cause != ctx.Err() {
+		ce.cause = cause
+	}
+	if ce.deadline.IsZero() && ce.cause == nil {
+		return err
+	}
+	return ce
+}
+
+// AllCanceled reports whether the batch is non-empty and all of its
+// underlying errors are (or wrap) context.Canceled,
+// which usually means everything stopped because the parent context was
+// canceled, instead of genuine work failures.
+func (eb *ErrBatch) AllCanceled() bool {
+	return eb.allIs(context.Canceled)
+}
+
+// AllDeadlineExceeded reports whether the batch is non-empty and all of its
+// underlying errors are (or wrap) context.DeadlineExceeded,
+// which usually means everything stopped because the deadline of the parent
+// context was exceeded, instead of genuine work failures.
+func (eb *ErrBatch) AllDeadlineExceeded() bool {
+	return eb.allIs(context.DeadlineExceeded)
+}
+
+func (eb *ErrBatch) allIs(target error) bool {
+	if len(eb.entries) == 0 {
+		return false
+	}
+	for _, e := range eb.entries {
+		if !errors.Is(e.err, target) {
+			return false
+		}
+	}
+	return true
+}
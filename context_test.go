@@ -0,0 +1,191 @@
+package errbatch_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/fishy/errbatch"
+)
+
+func TestAddContext(t *testing.T) {
+	cause := errors.New("upstream too slow")
+	deadline := time.Now().Add(-time.Second)
+	ctx, cancel := context.WithDeadlineCause(context.Background(), deadline, cause)
+	defer cancel()
+
+	var batch errbatch.ErrBatch
+	batch.AddContext(ctx, fmt.Errorf("fetch: %w", ctx.Err()))
+	batch.AddContext(ctx, errors.New("foo"))
+	batch.AddContext(ctx, nil)
+
+	errs := batch.GetErrors()
+	if len(errs) != 2 {
+		t.Fatalf("Expected 2 errors, got %#v", errs)
+	}
+	expected := fmt.Sprintf(
+		"fetch: context deadline exceeded (deadline: %s, cause: upstream too slow)",
+		deadline.Format(time.RFC3339Nano),
+	)
+	if errs[0].Error() != expected {
+		t.Errorf("Expected %q, got %q", expected, errs[0].Error())
+	}
+	if !errors.Is(errs[0], context.DeadlineExceeded) {
+		t.Errorf("Expected %v to match context.DeadlineExceeded", errs[0])
+	}
+	if !errors.Is(errs[0], cause) {
+		t.Errorf("Expected %v to match the cause", errs[0])
+	}
+	if errs[1].Error() != "foo" {
+		t.Errorf("Expected non-context errors to be unchanged, got %q", errs[1].Error())
+	}
+	if actual := fmt.Sprintf("%+v", errs[0]); actual != expected {
+		t.Errorf("Expected %q, got %q", expected, actual)
+	}
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	batch.Clear()
+	batch.AddContext(canceled, canceled.Err())
+	if err := batch.Compile(); err != context.Canceled {
+		t.Errorf("Expected context.Canceled without details unchanged, got %#v", err)
+	}
+
+	batch.Clear()
+	batch.AddContext(context.Background(), context.Canceled)
+	if err := batch.Compile(); err != context.Canceled {
+		t.Errorf("Expected errors to be unchanged when ctx is not done, got %#v", err)
+	}
+}
+
+func TestAddContextRecursiveFlatten(t *testing.T) {
+	cause := errors.New("shutting down")
+	ctx, cancel := context.WithCancelCause(context.Background())
+	cancel(cause)
+
+	batch := errbatch.New(errbatch.WithRecursiveFlatten())
+	batch.AddContext(ctx, ctx.Err())
+
+	errs := batch.GetErrors()
+	if len(errs) != 1 {
+		t.Fatalf("Expected 1 error, got %#v", errs)
+	}
+	const expected = "context canceled (cause: shutting down)"
+	if errs[0].Error() != expected {
+		t.Errorf("Expected %q, got %q", expected, errs[0].Error())
+	}
+	if !errors.Is(errs[0], context.Canceled) {
+		t.Errorf("Expected %v to match context.Canceled", errs[0])
+	}
+	if !errors.Is(errs[0], cause) {
+		t.Errorf("Expected %v to match the cause", errs[0])
+	}
+	var target *customError
+	batch.Clear()
+	ctx, cancel = context.WithCancelCause(context.Background())
+	cancel(&customError{})
+	batch.AddContext(ctx, ctx.Err())
+	if !errors.As(batch.Compile(), &target) {
+		t.Errorf("Expected errors.As to match the cause")
+	}
+}
+
+type traceKeyType struct{}
+
+var traceKey traceKeyType
+
+func TestWithTraceExtractor(t *testing.T) {
+	extract := func(ctx context.Context) string {
+		id, _ := ctx.Value(traceKey).(string)
+		return id
+	}
+	ctx := context.WithValue(context.Background(), traceKey, "abc123")
+
+	batch := errbatch.New(errbatch.WithTraceExtractor(extract))
+	batch.AddContext(ctx, errors.New("foo"))
+	batch.AddContext(context.Background(), errors.New("bar"))
+	batch.Add(errors.New("foobar"))
+
+	expected := []string{"abc123", "", ""}
+	if actual := batch.TraceIDs(); !slices.Equal(actual, expected) {
+		t.Errorf("Expected %q, got %q", expected, actual)
+	}
+	const verbose = "errbatch: total 3 error(s) in this batch:\n[trace abc123] foo\nbar\nfoobar"
+	if actual := fmt.Sprintf("%+v", batch); actual != verbose {
+		t.Errorf("Expected %q, got %q", verbose, actual)
+	}
+
+	cb := errbatch.NewConcurrent(errbatch.WithTraceExtractor(extract))
+	if !errbatch.AddToContext(errbatch.NewContext(ctx, cb), errors.New("foo")) {
+		t.Fatal("Expected AddToContext to return true")
+	}
+	cb.AddContext(ctx, errors.New("bar"))
+	if actual := cb.Snapshot().TraceIDs(); !slices.Equal(actual, []string{"abc123", "abc123"}) {
+		t.Errorf("Expected all errors to be tagged, got %q", actual)
+	}
+}
+
+func TestAllCanceled(t *testing.T) {
+	for _, c := range []struct {
+		label    string
+		errs     []error
+		canceled bool
+		deadline bool
+	}{
+		{
+			label: "empty",
+		},
+		{
+			label: "canceled",
+			errs: []error{
+				context.Canceled,
+				fmt.Errorf("call: %w", context.Canceled),
+			},
+			canceled: true,
+		},
+		{
+			label: "deadline",
+			errs: []error{
+				context.DeadlineExceeded,
+				fmt.Errorf("call: %w", context.DeadlineExceeded),
+			},
+			deadline: true,
+		},
+		{
+			label: "mixed",
+			errs: []error{
+				context.Canceled,
+				context.DeadlineExceeded,
+			},
+		},
+		{
+			label: "failure",
+			errs: []error{
+				context.Canceled,
+				errors.New("foo"),
+			},
+		},
+	} {
+		t.Run(c.label, func(t *testing.T) {
+			var batch errbatch.ErrBatch
+			for _, err := range c.errs {
+				batch.Add(err)
+			}
+			if actual := batch.AllCanceled(); actual != c.canceled {
+				t.Errorf("Expected AllCanceled %v, got %v", c.canceled, actual)
+			}
+			if actual := batch.AllDeadlineExceeded(); actual != c.deadline {
+				t.Errorf("Expected AllDeadlineExceeded %v, got %v", c.deadline, actual)
+			}
+		})
+	}
+}
+
+func TestAddToContext(t *testing.T) {
+	if errbatch.AddToContext(context.Background(), errors.New("foo")) {
+		t.Error("Expected AddToContext to return false without batch in context")
+	}
+}
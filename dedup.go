@@ -0,0 +1,99 @@
+package errbatch
+
+import (
+	"errors"
+	"reflect"
+)
+
+// DedupFunc reports whether a and b are the same error for WithDedup.
+type DedupFunc func(a, b error) bool
+
+// WithDedup makes the batch skip errors that are the same as (according to
+// equal) an error already in the batch,
+// so only the first occurrence of each error is kept.
+//
+// What "same" means depends on the errors,
+// for example DedupByMessage fits validation errors,
+// while DedupByRootType or DedupByIs fit network errors with varying messages.
+// Any func(a, b error) bool can also be used.
+//
+// ConcurrentErrBatch skips duplicates as they are added,
+// with Add serialized by a mutex.
+//
+// Deduplication compares each added error with all the errors in the batch,
+// so it's not recommended for very large batches.
+// Nil equal disables deduplication.
+func WithDedup(equal DedupFunc) Option {
+	return func(o *options) {
+		o.dedup = equal
+	}
+}
+
+// DedupByMessage is a DedupFunc treating errors with the same message as the
+// same.
+func DedupByMessage(a, b error) bool {
+	return a.Error() == b.Error()
+}
+
+// DedupByRootType is a DedupFunc treating errors with the same type of root
+// causes (the innermost error of the Unwrap() error chains) as the same.
+func DedupByRootType(a, b error) bool {
+	return reflect.TypeOf(rootCause(a)) == reflect.TypeOf(rootCause(b))
+}
+
+// DedupByIs returns a DedupFunc treating errors as the same when one matches
+// the other via errors.Is,
+// or both of them match the same target via errors.Is.
+//
+// For example, DedupByIs(context.DeadlineExceeded) keeps only one timeout,
+// regardless of how they are wrapped.
+func DedupByIs(targets ...error) DedupFunc {
+	return func(a, b error) bool {
+		if errors.Is(a, b) || errors.Is(b, a) {
+			return true
+		}
+		for _, target := range targets {
+			if errors.Is(a, target) && errors.Is(b, target) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func rootCause(err error) error {
+	for {
+		next := errors.Unwrap(err)
+		if next == nil {
+			return err
+		}
+		err = next
+	}
+}
+
+// dedup returns entries without the ones that are the same as either an
+// error already in the batch or an earlier one in entries,
+// according to WithDedup.
+func (eb *ErrBatch) dedup(entries []entry) []entry {
+	equal := eb.getOptions().dedup
+	if equal == nil {
+		return entries
+	}
+	kept := make([]entry, 0, len(entries))
+	for _, e := range entries {
+		if !containsEqual(eb.entries, e.err, equal) && !containsEqual(kept, e.err, equal) {
+			kept = append(kept, e)
+		}
+	}
+	countDropped(len(entries) - len(kept))
+	return kept
+}
+
+func containsEqual(entries []entry, err error, equal DedupFunc) bool {
+	for _, e := range entries {
+		if equal(e.err, err) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,119 @@
+package errbatch_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/fishy/errbatch"
+)
+
+func TestWithDedup(t *testing.T) {
+	for _, c := range []struct {
+		label    string
+		equal    errbatch.DedupFunc
+		errs     []error
+		expected []string
+	}{
+		{
+			label: "disabled",
+			errs: []error{
+				errors.New("foo"),
+				errors.New("foo"),
+			},
+			expected: []string{"foo", "foo"},
+		},
+		{
+			label: "message",
+			equal: errbatch.DedupByMessage,
+			errs: []error{
+				errors.New("foo"),
+				errors.New("bar"),
+				errors.New("foo"),
+			},
+			expected: []string{"foo", "bar"},
+		},
+		{
+			label: "root-type",
+			equal: errbatch.DedupByRootType,
+			errs: []error{
+				fmt.Errorf("foo: %w", customError{}),
+				customError{},
+				errors.New("bar"),
+				fmt.Errorf("foobar: %w", errors.New("baz")),
+			},
+			expected: []string{"foo: custom", "bar"},
+		},
+		{
+			label: "is",
+			equal: errbatch.DedupByIs(context.DeadlineExceeded),
+			errs: []error{
+				fmt.Errorf("call a: %w", context.DeadlineExceeded),
+				fmt.Errorf("call b: %w", context.DeadlineExceeded),
+				context.Canceled,
+				fmt.Errorf("call c: %w", context.Canceled),
+			},
+			expected: []string{
+				"call a: context deadline exceeded",
+				"context canceled",
+			},
+		},
+		{
+			label: "custom",
+			equal: func(a, b error) bool {
+				return len(a.Error()) == len(b.Error())
+			},
+			errs: []error{
+				errors.New("foo"),
+				errors.New("bar"),
+				errors.New("foobar"),
+			},
+			expected: []string{"foo", "foobar"},
+		},
+	} {
+		t.Run(c.label, func(t *testing.T) {
+			batch := errbatch.New(errbatch.WithDedup(c.equal))
+			for _, err := range c.errs {
+				batch.Add(err)
+			}
+			if actual := messages(batch.GetErrors()); !slices.Equal(actual, c.expected) {
+				t.Errorf("Expected %q, got %q", c.expected, actual)
+			}
+		})
+	}
+
+	t.Run("batch", func(t *testing.T) {
+		var other errbatch.ErrBatch
+		other.Add(errors.New("foo"))
+		other.Add(errors.New("bar"))
+		other.Add(errors.New("foo"))
+
+		batch := errbatch.New(errbatch.WithDedup(errbatch.DedupByMessage))
+		batch.Add(errors.New("bar"))
+		batch.Add(&other)
+		expected := []string{"bar", "foo"}
+		if actual := messages(batch.GetErrors()); !slices.Equal(actual, expected) {
+			t.Errorf("Expected %q, got %q", expected, actual)
+		}
+	})
+
+	t.Run("concurrent", func(t *testing.T) {
+		cb := errbatch.NewConcurrent(errbatch.WithDedup(errbatch.DedupByMessage))
+		cb.Add(errors.New("foo"))
+		cb.Add(errors.New("foo"))
+		expected := []string{"foo"}
+		if actual := messages(cb.GetErrors()); !slices.Equal(actual, expected) {
+			t.Errorf("Expected %q, got %q", expected, actual)
+		}
+	})
+}
+
+func messages(errs []error) []string {
+	msgs := make([]string, len(errs))
+	for i, err := range errs {
+		msgs[i] = err.Error()
+	}
+	return msgs
+}
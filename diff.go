@@ -0,0 +1,76 @@
+package errbatch
+
+import (
+	"errors"
+	"strings"
+)
+
+// Diff returns a report of the errors only in a, only in b,
+// and common to both, for test assertions and for comparing the failures
+// across retries of a batch job:
+//
+//	only in a:
+//		[0] connection refused
+//	only in b:
+//		[0] permission denied
+//	common:
+//		[0] not found
+//
+// Two errors are considered common when one matches the other via errors.Is,
+// or they have the same message.
+// Each error is matched at most once, so duplicated errors are reported as
+// many times as their counts differ.
+// Empty sections are omitted.
+//
+// It returns an empty string when there's no error only in a or only in b.
+// A nil batch is treated as empty.
+func Diff(a, b *ErrBatch) string {
+	var onlyA, common []error
+	onlyB := batchErrors(b)
+	for _, err := range batchErrors(a) {
+		i := indexSame(onlyB, err)
+		if i < 0 {
+			onlyA = append(onlyA, err)
+			continue
+		}
+		common = append(common, err)
+		onlyB = append(onlyB[:i], onlyB[i+1:]...)
+	}
+	if len(onlyA) == 0 && len(onlyB) == 0 {
+		return ""
+	}
+
+	var sections []string
+	for _, s := range []struct {
+		title string
+		errs  []error
+	}{
+		{title: "only in a", errs: onlyA},
+		{title: "only in b", errs: onlyB},
+		{title: "common", errs: common},
+	} {
+		if len(s.errs) > 0 {
+			sections = append(sections, s.title+":\n"+listErrors(s.errs))
+		}
+	}
+	return strings.Join(sections, "\n")
+}
+
+// batchErrors returns the underlying errors of eb, which can be nil.
+func batchErrors(eb *ErrBatch) []error {
+	if eb == nil {
+		return nil
+	}
+	return eb.GetErrors()
+}
+
+// indexSame returns the index of the first error in errs that's the same as
+// err, as defined by Diff, or -1.
+func indexSame(errs []error, err error) int {
+	for i, e := range errs {
+		if errors.Is(e, err) || errors.Is(err, e) || e.Error() == err.Error() {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,58 @@
+package errbatch_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/fishy/errbatch"
+)
+
+func TestDiff(t *testing.T) {
+	sentinel := errors.New("sentinel")
+	batchOf := func(errs ...error) *errbatch.ErrBatch {
+		batch := errbatch.New()
+		for _, err := range errs {
+			batch.Add(err)
+		}
+		return batch
+	}
+
+	for _, c := range []struct {
+		label    string
+		a, b     *errbatch.ErrBatch
+		expected string
+	}{
+		{
+			label: "nil",
+		},
+		{
+			label: "same",
+			a:     batchOf(errors.New("foo"), sentinel),
+			b:     batchOf(fmt.Errorf("wrapped: %w", sentinel), errors.New("foo")),
+		},
+		{
+			label:    "only-in-a",
+			a:        batchOf(errors.New("foo"), errors.New("bar")),
+			b:        batchOf(errors.New("bar")),
+			expected: "only in a:\n\t[0] foo\ncommon:\n\t[0] bar",
+		},
+		{
+			label:    "only-in-b",
+			b:        batchOf(errors.New("foo")),
+			expected: "only in b:\n\t[0] foo",
+		},
+		{
+			label:    "duplicated",
+			a:        batchOf(errors.New("foo"), errors.New("foo"), errors.New("bar")),
+			b:        batchOf(errors.New("foo"), errors.New("baz")),
+			expected: "only in a:\n\t[0] foo\n\t[1] bar\nonly in b:\n\t[0] baz\ncommon:\n\t[0] foo",
+		},
+	} {
+		t.Run(c.label, func(t *testing.T) {
+			if actual := errbatch.Diff(c.a, c.b); actual != c.expected {
+				t.Errorf("Expected %q, got %q", c.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package errbatch
+
+// Make sure all the batches satisfy Collector interface.
+var (
+	_ Collector = (*ErrBatch)(nil)
+	_ Collector = (*ConcurrentErrBatch)(nil)
+	_ Collector = (*ShardedErrBatch)(nil)
+)
+
+// Collector is the interface shared by the batches in this package to
+// collect errors.
+//
+// Libraries can accept a Collector to report the errors they encounter,
+// and let the callers decide how (or whether) to aggregate them:
+//
+//	func Process(items []Item, errs errbatch.Collector) {
+//		for _, item := range items {
+//			errs.Add(item.Process())
+//		}
+//	}
+//
+// Callers not interested in the errors pass Discard instead.
+type Collector interface {
+	Add(err error)
+}
+
+// Discard is a Collector whose Add does nothing.
+//
+// It's safe to be used concurrently.
+var Discard Collector = discard{}
+
+type discard struct{}
+
+func (discard) Add(error) {}
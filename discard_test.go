@@ -0,0 +1,31 @@
+package errbatch_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/fishy/errbatch"
+)
+
+func TestCollector(t *testing.T) {
+	process := func(c errbatch.Collector) {
+		c.Add(errors.New("foo"))
+		c.Add(nil)
+		c.Add(errors.New("bar"))
+	}
+
+	var batch errbatch.ErrBatch
+	process(&batch)
+	if n := len(batch.GetErrors()); n != 2 {
+		t.Errorf("Expected 2 errors, got %d", n)
+	}
+
+	var cb errbatch.ConcurrentErrBatch
+	process(&cb)
+	if n := cb.Len(); n != 2 {
+		t.Errorf("Expected 2 errors, got %d", n)
+	}
+
+	// Make sure Discard does not panic.
+	process(errbatch.Discard)
+}
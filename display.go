@@ -0,0 +1,143 @@
+package errbatch
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+// WithSeparator sets the separator between the messages of the underlying
+// errors in Error.
+//
+// The default separator is "; ".
+func WithSeparator(sep string) Option {
+	return func(o *options) {
+		o.separator = sep
+	}
+}
+
+// WithMaxDisplayed limits the number of messages displayed in Error to n.
+//
+// When there are more errors in the batch,
+// the rest are summarized as "... and N more".
+// The count in the header, and the verbose forms (e.g. %+v),
+// still include all the errors.
+//
+// n <= 0 means no limit, which is the default.
+func WithMaxDisplayed(n int) Option {
+	return func(o *options) {
+		o.maxDisplayed = n
+	}
+}
+
+// WithMaxMessageBytes truncates the message of each underlying error to at
+// most n bytes (without splitting UTF-8 characters) followed by "...",
+// in both Error and the verbose forms,
+// so a single huge error message can't blow up the message of the batch.
+//
+// It's applied after WithRedactor and does not include the type name added by
+// WithTypeNames.
+//
+// n <= 0 means no limit, which is the default.
+func WithMaxMessageBytes(n int) Option {
+	return func(o *options) {
+		o.maxMessageBytes = n
+	}
+}
+
+// WithRedactor sets a function to redact the message of each underlying
+// error (e.g. to remove secrets or personal data) before it's displayed,
+// in both Error and the verbose forms.
+//
+// It does not change the underlying errors themselves,
+// so GetErrors still returns the original errors.
+func WithRedactor(redact func(msg string) string) Option {
+	return func(o *options) {
+		o.redactor = redact
+	}
+}
+
+// WithTypeNames prefixes the message of each underlying error with its type
+// name, e.g. "*net.OpError: dial tcp 127.0.0.1:80: connection refused",
+// in both Error and the verbose forms.
+//
+// It helps to distinguish errors of different types with the same message.
+// The wrappers added by this package (e.g. AddCoded and AddNamed) are
+// skipped, so the type name is always the one of the original error.
+func WithTypeNames() Option {
+	return func(o *options) {
+		o.typeNames = true
+	}
+}
+
+// typeName returns the type name of err, skipping the wrappers added by this
+// package.
+func typeName(err error) string {
+	for {
+		switch e := err.(type) {
+		case *codedError:
+			err = e.err
+		case *namedError:
+			err = e.err
+		case *DecodedError:
+			return e.TypeName
+		default:
+			return fmt.Sprintf("%T", err)
+		}
+	}
+}
+
+// render renders err with format (%v or %+v) for display,
+// according to WithTypeNames and WithRedactor.
+func (opts *options) render(format string, err error) string {
+	msg := opts.truncate(opts.redact(formatError(format, err)))
+	if opts.typeNames {
+		return typeName(err) + ": " + msg
+	}
+	return msg
+}
+
+// redact redacts msg with the redactor set by WithRedactor, if any.
+func (opts *options) redact(msg string) string {
+	if opts.redactor == nil {
+		return msg
+	}
+	return opts.redactor(msg)
+}
+
+// truncate truncates msg according to WithMaxMessageBytes.
+func (opts *options) truncate(msg string) string {
+	n := opts.maxMessageBytes
+	if n <= 0 || len(msg) <= n {
+		return msg
+	}
+	for n > 0 && !utf8.RuneStart(msg[n]) {
+		n--
+	}
+	return msg[:n] + "..."
+}
+
+// Formatter formats the underlying errors of a batch into its message.
+type Formatter interface {
+	FormatBatch(errs []error) string
+}
+
+// FormatterFunc is the function adapter for Formatter.
+type FormatterFunc func(errs []error) string
+
+// FormatBatch implements Formatter.
+func (f FormatterFunc) FormatBatch(errs []error) string {
+	return f(errs)
+}
+
+// WithFormatter replaces the single-line message of the batch (Error,
+// and all verbs other than %+v) with the result of f.
+//
+// When it's used, the header, WithSeparator, WithMaxDisplayed,
+// and WithRedactor do not apply to the message,
+// it's up to f to handle all of them.
+// The verbose forms (e.g. %+v) are not affected.
+func WithFormatter(f Formatter) Option {
+	return func(o *options) {
+		o.formatter = f
+	}
+}
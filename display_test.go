@@ -0,0 +1,158 @@
+package errbatch_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/fishy/errbatch"
+)
+
+func TestDisplayOptions(t *testing.T) {
+	errs := []error{errors.New("foo"), errors.New("secret bar"), errors.New("foobar")}
+	redact := func(msg string) string {
+		return strings.ReplaceAll(msg, "secret", "***")
+	}
+
+	for _, c := range []struct {
+		label   string
+		opts    []errbatch.Option
+		message string
+		verbose string
+	}{
+		{
+			label:   "default",
+			message: "errbatch: total 3 error(s) in this batch: foo; secret bar; foobar",
+			verbose: "errbatch: total 3 error(s) in this batch:\nfoo\nsecret bar\nfoobar",
+		},
+		{
+			label:   "separator",
+			opts:    []errbatch.Option{errbatch.WithSeparator(" | ")},
+			message: "errbatch: total 3 error(s) in this batch: foo | secret bar | foobar",
+			verbose: "errbatch: total 3 error(s) in this batch:\nfoo\nsecret bar\nfoobar",
+		},
+		{
+			label:   "max-displayed",
+			opts:    []errbatch.Option{errbatch.WithMaxDisplayed(1)},
+			message: "errbatch: total 3 error(s) in this batch: foo; ... and 2 more",
+			verbose: "errbatch: total 3 error(s) in this batch:\nfoo\nsecret bar\nfoobar",
+		},
+		{
+			label:   "max-displayed-not-reached",
+			opts:    []errbatch.Option{errbatch.WithMaxDisplayed(3)},
+			message: "errbatch: total 3 error(s) in this batch: foo; secret bar; foobar",
+			verbose: "errbatch: total 3 error(s) in this batch:\nfoo\nsecret bar\nfoobar",
+		},
+		{
+			label:   "redactor",
+			opts:    []errbatch.Option{errbatch.WithRedactor(redact)},
+			message: "errbatch: total 3 error(s) in this batch: foo; *** bar; foobar",
+			verbose: "errbatch: total 3 error(s) in this batch:\nfoo\n*** bar\nfoobar",
+		},
+	} {
+		t.Run(c.label, func(t *testing.T) {
+			batch := errbatch.New(c.opts...)
+			for _, err := range errs {
+				batch.Add(err)
+			}
+			if actual := batch.Error(); actual != c.message {
+				t.Errorf("Expected %q, got %q", c.message, actual)
+			}
+			if actual := fmt.Sprintf("%+v", batch); actual != c.verbose {
+				t.Errorf("Expected %q, got %q", c.verbose, actual)
+			}
+		})
+	}
+}
+
+func TestSetDefaults(t *testing.T) {
+	t.Cleanup(func() {
+		errbatch.SetDefaults()
+	})
+
+	var zero errbatch.ErrBatch
+	zero.Add(errors.New("foo"))
+	zero.Add(errors.New("bar"))
+
+	errbatch.SetDefaults(errbatch.WithSeparator(" | "))
+	const expected = "errbatch: total 2 error(s) in this batch: foo | bar"
+	if actual := zero.Error(); actual != expected {
+		t.Errorf("Expected %q, got %q", expected, actual)
+	}
+
+	batch := errbatch.New(errbatch.WithMaxDisplayed(1))
+	batch.Add(&zero)
+	const limited = "errbatch: total 2 error(s) in this batch: foo | ... and 1 more"
+	if actual := batch.Error(); actual != limited {
+		t.Errorf("Expected options to be applied on top of defaults %q, got %q", limited, actual)
+	}
+
+	errbatch.SetDefaults()
+	const restored = "errbatch: total 2 error(s) in this batch: foo; bar"
+	if actual := zero.Error(); actual != restored {
+		t.Errorf("Expected %q, got %q", restored, actual)
+	}
+	if actual := batch.Error(); actual != limited {
+		t.Errorf("Expected batch created by New to keep its options %q, got %q", limited, actual)
+	}
+}
+
+func TestWithFormatter(t *testing.T) {
+	f := errbatch.FormatterFunc(func(errs []error) string {
+		msgs := make([]string, len(errs))
+		for i, err := range errs {
+			msgs[i] = "- " + err.Error()
+		}
+		return strings.Join(msgs, "\n")
+	})
+	batch := errbatch.New(errbatch.WithFormatter(f))
+	batch.Add(errors.New("foo"))
+	batch.Add(errors.New("bar"))
+
+	const expected = "- foo\n- bar"
+	if actual := batch.Error(); actual != expected {
+		t.Errorf("Expected %q, got %q", expected, actual)
+	}
+	if actual := fmt.Sprintf("%v", batch); actual != expected {
+		t.Errorf("Expected %q, got %q", expected, actual)
+	}
+	const verbose = "errbatch: total 2 error(s) in this batch:\nfoo\nbar"
+	if actual := fmt.Sprintf("%+v", batch); actual != verbose {
+		t.Errorf("Expected %q, got %q", verbose, actual)
+	}
+}
+
+func TestWithTypeNames(t *testing.T) {
+	batch := errbatch.New(errbatch.WithTypeNames())
+	batch.Add(errors.New("foo"))
+	batch.AddCoded("code", customError{})
+	batch.AddNamed("name", customError{})
+
+	const expected = "errbatch: total 3 error(s) in this batch: " +
+		"*errors.errorString: foo; " +
+		"errbatch_test.customError: custom; " +
+		"errbatch_test.customError: name: custom"
+	if actual := batch.Error(); actual != expected {
+		t.Errorf("Expected %q, got %q", expected, actual)
+	}
+	const verbose = "errbatch: total 3 error(s) in this batch:\n" +
+		"*errors.errorString: foo\n" +
+		"errbatch_test.customError: custom\n" +
+		"errbatch_test.customError: name: custom"
+	if actual := fmt.Sprintf("%+v", batch); actual != verbose {
+		t.Errorf("Expected %q, got %q", verbose, actual)
+	}
+}
+
+func TestWithMaxMessageBytes(t *testing.T) {
+	batch := errbatch.New(errbatch.WithMaxMessageBytes(4))
+	batch.Add(errors.New("foo"))
+	batch.Add(errors.New("foobar"))
+	batch.Add(errors.New("fooébar"))
+
+	expected := "errbatch: total 3 error(s) in this batch: foo; foob...; foo..."
+	if actual := batch.Error(); actual != expected {
+		t.Errorf("Expected %q, got %q", expected, actual)
+	}
+}
@@ -32,6 +32,7 @@
 //         return batch.Compile()
 //     }
 //
-// This package is not thread-safe.
-// The same batch should not be operated on different goroutines.
+// ErrBatch is not thread-safe.
+// The same ErrBatch should not be operated on different goroutines,
+// use ConcurrentErrBatch (or Group) for that instead.
 package errbatch
@@ -30,7 +30,7 @@ func Example() {
 	var newBatch errbatch.ErrBatch
 	err = errors.New("foobar")
 	newBatch.Add(err)
-	newBatch.Add(batch)
+	newBatch.Add(&batch)
 	fmt.Printf("3: %v\n", newBatch.Compile())
 
 	// Output:
@@ -0,0 +1,50 @@
+package errbatch
+
+import (
+	"os"
+	"strconv"
+)
+
+// The environment variables read at init to set the default options,
+// so operators can tune the batches of deployed binaries without a redeploy.
+//
+// Each of them is optional,
+// unset or invalid values are ignored and the built-in defaults are used.
+// They are applied via SetDefaults,
+// so calling SetDefaults in the application overrides all of them.
+const (
+	// EnvMaxErrors is the environment variable setting the max number of
+	// errors retained by each batch, as WithRingBuffer.
+	EnvMaxErrors = "ERRBATCH_MAX_ERRORS"
+
+	// EnvMaxMessageBytes is the environment variable setting the max length of
+	// the message of each error, as WithMaxMessageBytes.
+	EnvMaxMessageBytes = "ERRBATCH_MAX_MESSAGE_BYTES"
+
+	// EnvDedup is the environment variable enabling (when parsed as true by
+	// strconv.ParseBool) deduplication by messages, as
+	// WithDedup(DedupByMessage).
+	EnvDedup = "ERRBATCH_DEDUP"
+)
+
+func init() {
+	if opts := envOptions(os.Getenv); len(opts) > 0 {
+		SetDefaults(opts...)
+	}
+}
+
+// envOptions returns the options set by the environment variables read via
+// getenv.
+func envOptions(getenv func(key string) string) []Option {
+	var opts []Option
+	if n, err := strconv.Atoi(getenv(EnvMaxErrors)); err == nil && n > 0 {
+		opts = append(opts, WithRingBuffer(n))
+	}
+	if n, err := strconv.Atoi(getenv(EnvMaxMessageBytes)); err == nil && n > 0 {
+		opts = append(opts, WithMaxMessageBytes(n))
+	}
+	if dedup, err := strconv.ParseBool(getenv(EnvDedup)); err == nil && dedup {
+		opts = append(opts, WithDedup(DedupByMessage))
+	}
+	return opts
+}
@@ -0,0 +1,61 @@
+package errbatch
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestEnvOptions(t *testing.T) {
+	for _, c := range []struct {
+		label    string
+		env      map[string]string
+		expected string
+	}{
+		{
+			label:    "unset",
+			expected: "errbatch: total 4 error(s) in this batch: foo; foo; bar; baz",
+		},
+		{
+			label: "invalid",
+			env: map[string]string{
+				EnvMaxErrors:       "many",
+				EnvMaxMessageBytes: "-1",
+				EnvDedup:           "maybe",
+			},
+			expected: "errbatch: total 4 error(s) in this batch: foo; foo; bar; baz",
+		},
+		{
+			label: "max-errors",
+			env: map[string]string{
+				EnvMaxErrors: "2",
+			},
+			expected: "errbatch: total 2 error(s) in this batch: bar; baz",
+		},
+		{
+			label: "max-message-bytes",
+			env: map[string]string{
+				EnvMaxMessageBytes: "2",
+			},
+			expected: "errbatch: total 4 error(s) in this batch: fo...; fo...; ba...; ba...",
+		},
+		{
+			label: "dedup",
+			env: map[string]string{
+				EnvDedup: "true",
+			},
+			expected: "errbatch: total 3 error(s) in this batch: foo; bar; baz",
+		},
+	} {
+		t.Run(c.label, func(t *testing.T) {
+			batch := New(envOptions(func(key string) string {
+				return c.env[key]
+			})...)
+			for _, msg := range []string{"foo", "foo", "bar", "baz"} {
+				batch.Add(errors.New(msg))
+			}
+			if actual := batch.Error(); actual != c.expected {
+				t.Errorf("Expected %q, got %q", c.expected, actual)
+			}
+		})
+	}
+}
@@ -3,79 +3,603 @@ package errbatch
 import (
 	"errors"
 	"fmt"
+	"io"
+	"reflect"
+	"strconv"
 	"strings"
+	"time"
+
+	"golang.org/x/xerrors"
 )
 
 // Make sure *ErrBatch satisfies error interface.
-// (ErrBatch satisfies error interface as well.)
 var _ error = (*ErrBatch)(nil)
 
+// Make sure *ErrBatch satisfies xerrors.Formatter interface.
+var _ xerrors.Formatter = (*ErrBatch)(nil)
+
 // ErrBatch is an error that can contain multiple errors.
 //
 // The zero value of ErrBatch is valid (with no errors) and ready to use.
+//
+// All the methods of ErrBatch have pointer receivers,
+// so only *ErrBatch is an error.
+// An ErrBatch must not be copied after first use,
+// as errors added to the copy would be silently lost from the original
+// (go vet's copylocks check reports such copies).
+// Use errors.As with a *ErrBatch variable to get a copy of a batch instead:
+//
+//	var batch *errbatch.ErrBatch
+//	if errors.As(err, &batch) {
+//		// use batch
+//	}
 type ErrBatch struct {
-	errors []error
+	noCopy noCopy
+
+	entries []entry
+
+	opts *options
+
+	// traceparent set by SetTraceparent.
+	traceparent string
+
+	// dropped is the number of errors evicted by the retention policy (e.g.
+	// WithTopN).
+	dropped int
+
+	// skipped is the number of errors skipped by WithIgnore.
+	skipped int
+
+	// ret indexes the entries for the retention policy,
+	// nil when it needs to be rebuilt.
+	ret *retentionState
+}
+
+// noCopy may be embedded into structs which must not be copied after the
+// first use, to be detected by go vet's copylocks check.
+//
+// See https://golang.org/issues/8005#issuecomment-190753527.
+type noCopy struct{}
+
+// Lock is a no-op used by go vet's copylocks check.
+func (*noCopy) Lock() {}
+
+// Unlock is a no-op used by go vet's copylocks check.
+func (*noCopy) Unlock() {}
+
+// entry is a single error in the batch, with optional metadata.
+type entry struct {
+	err error
+
+	// pc of the call site that added err, 0 means not captured.
+	pc uintptr
+
+	// stack of the call site that added err, nil means not captured.
+	stack []uintptr
+
+	// meta is only allocated when any of the metadata is set,
+	// so batches not using them don't pay for them.
+	// It's shared by the copies of the entry (e.g. Snapshot),
+	// use getMeta to read it and editMeta to modify it.
+	meta *entryMeta
+}
+
+// entryMeta is the optional metadata of an entry.
+type entryMeta struct {
+	// goroutine that added err, set by WithDebug, 0 means not captured.
+	goroutine uint64
+
+	// seq is the process-wide sequence number of the entry,
+	// assigned when err is first added into any batch created with
+	// WithSequenceOrder, 0 means not assigned.
+	seq uint64
+
+	// weight of the entry set by AddWeighted,
+	// only used when weighted is true.
+	weight   float64
+	weighted bool
+
+	// traceID of the context the entry was added with,
+	// extracted by WithTraceExtractor.
+	traceID string
+
+	// at is the time err was first added into any batch created with
+	// WithTimestamps, zero means unknown.
+	at time.Time
+
+	// fingerprint of err and the number of times errors with the same
+	// fingerprint were added after err, set by WithUniqueLRU.
+	fingerprint string
+	repeats     int
+
+	// order of the entry in the batch for the retention policy,
+	// see retentionState,
+	// and its rank for WithTopN.
+	order uint64
+	rank  int
+}
+
+var noMeta entryMeta
+
+// getMeta returns the metadata of the entry, which must not be modified.
+func (e *entry) getMeta() *entryMeta {
+	if e.meta == nil {
+		return &noMeta
+	}
+	return e.meta
+}
+
+// editMeta returns a copy of the metadata of the entry to be modified,
+// so the other copies of the entry are not affected.
+func (e *entry) editMeta() *entryMeta {
+	m := new(entryMeta)
+	if e.meta != nil {
+		*m = *e.meta
+	}
+	e.meta = m
+	return m
 }
 
 // Error satisfies the error interface.
-func (eb ErrBatch) Error() string {
+func (eb *ErrBatch) Error() string {
+	return eb.message("%+v")
+}
+
+// message returns the header and all the underlying errors formatted with
+// format in a single line.
+func (eb *ErrBatch) message(format string) string {
+	opts := eb.getOptions()
+	if opts.formatter != nil {
+		return opts.formatter.FormatBatch(eb.GetErrors())
+	}
+	sep := opts.separator
+	header := opts.getHeader(len(eb.entries))
+
+	shown := eb.entries
+	var more string
+	if opts.maxDisplayed > 0 && len(shown) > opts.maxDisplayed {
+		shown = shown[:opts.maxDisplayed]
+		more = fmt.Sprintf("... and %d more", len(eb.entries)-len(shown))
+	}
+
+	// Format all the errors first to grow the builder only once.
+	msgs := make([]string, len(shown))
+	size := len(header) + len(sep)*len(eb.entries) + len(more)
+	for i, e := range shown {
+		msgs[i] = opts.render(format, e.err)
+		size += len(msgs[i])
+	}
+	if more != "" {
+		msgs = append(msgs, more)
+	}
+
 	var builder strings.Builder
-	fmt.Fprintf(
-		&builder,
-		"errbatch: total %d error(s) in this batch",
-		len(eb.errors),
-	)
-	for i, err := range eb.errors {
-		if i == 0 {
+	builder.Grow(size)
+	builder.WriteString(header)
+	for i, msg := range msgs {
+		if i > 0 {
+			builder.WriteString(sep)
+		} else if header != "" {
 			builder.WriteString(": ")
-		} else {
-			builder.WriteString("; ")
 		}
-		fmt.Fprintf(&builder, "%+v", err)
+		builder.WriteString(msg)
+	}
+	return builder.String()
+}
+
+// formatError formats err with format (%v or %+v).
+//
+// For errors not implementing fmt.Formatter, both are the same as Error,
+// so it's called directly (via errorMessage) to avoid the overhead of fmt.
+func formatError(format string, err error) string {
+	if _, ok := err.(fmt.Formatter); !ok {
+		return errorMessage(err)
+	}
+	return fmt.Sprintf(format, err)
+}
+
+// errorMessage returns err.Error(),
+// or falls back to fmt if Error panics (e.g. on a typed nil pointer).
+//
+// All the messages of the underlying errors should be rendered by it.
+func errorMessage(err error) (msg string) {
+	defer func() {
+		if recover() != nil {
+			msg = fmt.Sprint(err)
+		}
+	}()
+	return err.Error()
+}
+
+// Format implements fmt.Formatter.
+//
+// %+v prints the verbose form of the batch:
+// the header followed by each of the underlying errors on its own line,
+// printed with %+v.
+// When the batch is created with WithCaller,
+// each line is prefixed with the call site that added the error.
+//
+// All other verbs are applied to the string returned by Error.
+func (eb *ErrBatch) Format(s fmt.State, verb rune) {
+	if verb == 'v' && s.Flag('+') {
+		io.WriteString(s, eb.verbose())
+		return
+	}
+	fmt.Fprintf(s, fmt.FormatString(s, verb), eb.Error())
+}
+
+// WriteTo implements io.WriterTo.
+//
+// It writes the verbose form of the batch to w,
+// the same as formatting it with %+v.
+func (eb *ErrBatch) WriteTo(w io.Writer) (int64, error) {
+	n, err := io.WriteString(w, eb.verbose())
+	return int64(n), err
+}
+
+// FormatError implements xerrors.Formatter.
+//
+// It prints the header and the messages of the underlying errors in a single
+// line.
+// When detail is requested (e.g. %+v through xerrors-aware printers),
+// each of the underlying errors is also printed with its own detail on its
+// own line, prefixed with the call site if captured.
+func (eb *ErrBatch) FormatError(p xerrors.Printer) error {
+	p.Print(eb.message("%v"))
+	if p.Detail() {
+		eb.printDetail(p)
+	}
+	return nil
+}
+
+// printDetail prints each of the underlying errors with its own detail on its
+// own line, prefixed with the call site if captured.
+func (eb *ErrBatch) printDetail(p xerrors.Printer) {
+	opts := eb.getOptions()
+	for _, e := range eb.entries {
+		p.Print("\n")
+		if loc := e.location(); loc != "" {
+			p.Print(loc, ": ")
+		}
+		if id := e.getMeta().traceID; id != "" {
+			p.Print("[trace ", id, "] ")
+		}
+		p.Print(opts.render("%+v", e.err))
+	}
+}
+
+func (eb *ErrBatch) verbose() string {
+	var builder strings.Builder
+	opts := eb.getOptions()
+	header := opts.getHeader(len(eb.entries))
+	builder.WriteString(header)
+	if header != "" && len(eb.entries) > 0 {
+		builder.WriteString(":")
+	}
+	for i, e := range eb.entries {
+		if i > 0 || header != "" {
+			builder.WriteString("\n")
+		}
+		e.writeVerbose(&builder, opts)
 	}
 	return builder.String()
 }
 
+// writeVerbose writes the entry formatted with %+v,
+// prefixed with the goroutine, the call site and the trace ID if captured.
+func (e entry) writeVerbose(w io.Writer, opts *options) {
+	meta := e.getMeta()
+	if meta.goroutine != 0 {
+		io.WriteString(w, "[goroutine ")
+		io.WriteString(w, strconv.FormatUint(meta.goroutine, 10))
+		io.WriteString(w, "] ")
+	}
+	if loc := e.location(); loc != "" {
+		io.WriteString(w, loc)
+		io.WriteString(w, ": ")
+	}
+	if meta.traceID != "" {
+		io.WriteString(w, "[trace ")
+		io.WriteString(w, meta.traceID)
+		io.WriteString(w, "] ")
+	}
+	io.WriteString(w, opts.render("%+v", e.err))
+	if opts.wrapChain {
+		writeChain(w, opts, e.err, 1)
+	}
+}
+
 // As implements helper interface for errors.As.
-func (eb ErrBatch) As(v interface{}) bool {
-	if target, ok := v.(*ErrBatch); ok {
-		target.errors = eb.GetErrors()
+//
+// The supported targets are **ErrBatch,
+// and pointers to pointers of types embedding ErrBatch.
+// A non-nil pointer of the latter is updated in place,
+// keeping its other fields.
+// Either way the target gets a copy of the errors, options, traceparent,
+// and the Dropped and Skipped counts of the batch.
+//
+// Note that since ErrBatch only implements error with pointer receivers,
+// errors.As panics with a *ErrBatch target
+// (e.g. "var batch errbatch.ErrBatch; errors.As(err, &batch)"),
+// use a *ErrBatch variable (a **ErrBatch target) instead.
+func (eb *ErrBatch) As(v interface{}) bool {
+	switch target := v.(type) {
+	case batchSetter:
+		target.setBatch(eb)
+		return true
+	case **ErrBatch:
+		*target = new(ErrBatch)
+		(*target).setBatch(eb)
 		return true
 	}
-	return false
+
+	val := reflect.ValueOf(v)
+	if val.Kind() != reflect.Pointer || val.IsNil() {
+		return false
+	}
+	ptr := val.Elem()
+	if ptr.Kind() != reflect.Pointer || !ptr.Type().Implements(batchSetterType) {
+		return false
+	}
+	if ptr.IsNil() {
+		ptr.Set(reflect.New(ptr.Type().Elem()))
+	}
+	ptr.Interface().(batchSetter).setBatch(eb)
+	return true
+}
+
+var batchSetterType = reflect.TypeFor[batchSetter]()
+
+// batchSetter is implemented by *ErrBatch,
+// and (via method promotion) pointers to types embedding ErrBatch.
+type batchSetter interface {
+	setBatch(src *ErrBatch)
+}
+
+// setBatch replaces eb with a copy of src.
+func (eb *ErrBatch) setBatch(src *ErrBatch) {
+	eb.entries = src.copyEntries()
+	eb.opts = src.opts
+	eb.traceparent = src.traceparent
+	eb.dropped = src.dropped
+	eb.skipped = src.skipped
+	eb.ret = nil
+}
+
+// Is implements helper interface for errors.Is.
+//
+// It reports true when target is also an *ErrBatch containing the same
+// number of errors,
+// and each of the errors in this batch matches (errors.Is) the error at the
+// same position in target.
+func (eb *ErrBatch) Is(target error) bool {
+	other, ok := target.(*ErrBatch)
+	if !ok || other == nil {
+		return false
+	}
+	return entriesMatch(eb.entries, other.entries)
+}
+
+// entriesMatch reports whether entries and targets have the same length,
+// and each error in entries matches (errors.Is) the one at the same position
+// in targets.
+func entriesMatch(entries, targets []entry) bool {
+	if len(entries) != len(targets) {
+		return false
+	}
+	for i, e := range entries {
+		if !errors.Is(e.err, targets[i].err) {
+			return false
+		}
+	}
+	return true
 }
 
 // Unwrap implements the hidden errors interface.
 //
 // When the batch contains exactly one error, that error is returned.
 // It returns nil otherwise.
-func (eb ErrBatch) Unwrap() error {
-	if len(eb.errors) == 1 {
-		return eb.errors[0]
+func (eb *ErrBatch) Unwrap() error {
+	if len(eb.entries) == 1 {
+		return eb.entries[0].err
 	}
 	return nil
 }
 
+// appendEntries appends entries to the batch,
+// skipping duplicates according to WithDedup (or merging them according to
+// WithUniqueLRU),
+// and evicting errors according to the retention policy (e.g. WithTopN).
+//
+// It returns the number of entries actually appended.
+func (eb *ErrBatch) appendEntries(entries ...entry) int {
+	entries = eb.dedup(entries)
+	switch opts := eb.getOptions(); {
+	case opts.fingerprint != nil:
+		eb.addUnique(entries, opts)
+	case opts.rank != nil && opts.maxErrors > 0:
+		eb.addRanked(entries, opts)
+	default:
+		eb.entries = append(eb.entries, entries...)
+		eb.retain()
+	}
+	return len(entries)
+}
+
 func (eb *ErrBatch) addBatch(batch *ErrBatch) {
-	eb.errors = append(eb.errors, batch.errors...)
+	eb.addEntries(batch.entries)
+}
+
+// addEntries adds entries flattened from another batch.
+func (eb *ErrBatch) addEntries(entries []entry) {
+	n := eb.appendEntries(entries...)
+	eb.sortBySeq()
+	countAdded(n)
+}
+
+// flatten returns the entries of the underlying errors if err is a batch,
+// or false otherwise.
+//
+// The entries of the errors from batches other than ErrBatch are created by
+// newEntry.
+//
+// Besides ErrBatch (and types implementing As for ErrBatch),
+// batches from other packages providing GetErrors() []error
+// (e.g. baseplate.go's batcherror.BatchError and errorsbp.Batch)
+// are also recognized.
+//
+// Only the Unwrap() error chain of err is checked,
+// so a batch inside an aggregated error (e.g. errors.Join) is not mistaken
+// for err itself, which would drop the other errors it aggregates.
+func flatten(err error, newEntry func(error) entry) ([]entry, bool) {
+	return flattenAs(err, asChain, newEntry)
+}
+
+// flattenAs is flatten with as deciding which errors are batches,
+// either asChain or asDirect.
+func flattenAs(
+	err error,
+	as func(err error, target any) bool,
+	newEntry func(error) entry,
+) ([]entry, bool) {
+	var batch ErrBatch
+	if as(err, &batch) {
+		return batch.entries, true
+	}
+
+	var foreign interface {
+		GetErrors() []error
+	}
+	if as(err, &foreign) {
+		var entries []entry
+		for _, err := range foreign.GetErrors() {
+			if err == nil {
+				continue
+			}
+			if as(err, &batch) {
+				entries = append(entries, batch.entries...)
+			} else {
+				entries = append(entries, newEntry(err))
+			}
+		}
+		return entries, true
+	}
+
+	return nil, false
+}
+
+// asChain is errors.As but only follows Unwrap() error,
+// not Unwrap() []error.
+func asChain(err error, target any) bool {
+	for err != nil {
+		if asDirect(err, target) {
+			return true
+		}
+		u, ok := err.(interface{ Unwrap() error })
+		if !ok {
+			return false
+		}
+		err = u.Unwrap()
+	}
+	return false
+}
+
+// asDirect is errors.As without following any Unwrap,
+// so only err itself is checked.
+func asDirect(err error, target any) bool {
+	val := reflect.ValueOf(target).Elem()
+	if reflect.TypeOf(err).AssignableTo(val.Type()) {
+		val.Set(reflect.ValueOf(err))
+		return true
+	}
+	x, ok := err.(interface{ As(any) bool })
+	return ok && x.As(target)
 }
 
 // Add adds an error into the batch.
 //
 // If the error is also an ErrBatch,
 // its underlying error(s) will be added instead of the ErrBatch itself.
+// The same applies to batches from other packages providing
+// GetErrors() []error,
+// for example batcherror.BatchError from github.com/reddit/baseplate.go.
 //
 // Nil error will be skipped.
 func (eb *ErrBatch) Add(err error) {
+	eb.add(err, nil)
+}
+
+// With adds an error into the batch the same as Add,
+// and returns the batch itself for chaining:
+//
+//	return errbatch.New().With(err0).With(err1).Compile()
+func (eb *ErrBatch) With(err error) *ErrBatch {
+	eb.add(err, nil)
+	return eb
+}
+
+// AddCall calls fn and adds the error it returns into the batch.
+//
+// It's useful with defer, as fn is evaluated at the time of the defer
+// statement but only called when the surrounding function returns:
+//
+//	defer batch.AddCall(f.Close)
+//
+// Nil fn will be skipped.
+func (eb *ErrBatch) AddCall(fn func() error) {
+	if fn == nil {
+		return
+	}
+	eb.add(fn(), nil)
+}
+
+// add is the implementation of Add.
+//
+// When modify is non-nil, the entry of err is modified by it before added
+// (e.g. to wrap err or to attach metadata).
+// If err is also a batch,
+// each of its underlying error(s) will be modified and added instead.
+//
+// It must be called directly from the exported method (e.g. Add).
+func (eb *ErrBatch) add(err error, modify func(*entry)) {
 	if err == nil {
 		return
 	}
 
-	var batch ErrBatch
-	if errors.As(err, &batch) {
-		eb.addBatch(&batch)
-	} else {
-		eb.errors = append(eb.errors, err)
+	opts := eb.getOptions()
+	site := opts.callSite(1)
+	if entries, ok := opts.flatten(err, site.newEntry); ok {
+		var skipped int
+		entries, skipped = opts.filter(entries)
+		eb.skipped += skipped
+		if modify != nil {
+			for i := range entries {
+				modify(&entries[i])
+			}
+		}
+		eb.addEntries(entries)
+		return
+	}
+
+	if opts.ignored(err) {
+		eb.skipped++
+		countDropped(1)
+		return
+	}
+	e := site.newEntry(err)
+	if modify != nil {
+		modify(&e)
+	}
+	countAdded(eb.appendEntries(e))
+}
+
+// WithNoSingleUnwrap makes Compile (and CompileValue) return the batch even
+// when it contains exactly one error,
+// so callers can always rely on getting a batch from a non-nil result for
+// uniform handling.
+func WithNoSingleUnwrap() Option {
+	return func(o *options) {
+		o.noSingleUnwrap = true
 	}
 }
 
@@ -84,28 +608,110 @@ func (eb *ErrBatch) Add(err error) {
 // If the batch contains zero errors, it will return nil.
 //
 // If the batch contains exactly one error,
-// that underlying error will be returned.
+// that underlying error will be returned,
+// unless WithNoSingleUnwrap is used.
 //
 // Otherwise, the batch itself will be returned.
+//
+// The first Compile also delivers the result to the Notifier set by
+// WithNotifier, if any.
 func (eb *ErrBatch) Compile() error {
-	switch len(eb.errors) {
-	case 0:
+	countCompiled()
+	return eb.notify(eb.compile())
+}
+
+// notify delivers err to the Notifier set by WithNotifier, if any,
+// and returns err.
+func (eb *ErrBatch) notify(err error) error {
+	if n := eb.getOptions().notifier; n != nil {
+		n.Notify(err)
+	}
+	return err
+}
+
+func (eb *ErrBatch) compile() error {
+	switch {
+	case len(eb.entries) == 0:
 		return nil
-	case 1:
-		return eb.errors[0]
+	case len(eb.entries) == 1 && !eb.getOptions().noSingleUnwrap:
+		return eb.entries[0].err
 	default:
 		return eb
 	}
 }
 
+// TryCompile compiles the batch the same as Compile,
+// and also reports whether the batch contains any error.
+//
+// It's useful to branch on the result without comparing the returned error
+// against nil:
+//
+//	if err, ok := batch.TryCompile(); ok {
+//		return fmt.Errorf("sync failed: %w", err)
+//	}
+func (eb *ErrBatch) TryCompile() (error, bool) {
+	return eb.Compile(), len(eb.entries) > 0
+}
+
+// ErrorOrNil returns nil if the batch is nil or contains zero errors,
+// and the batch itself otherwise.
+//
+// It has the same semantics as ErrorOrNil in
+// github.com/hashicorp/go-multierror to ease migrations from it.
+// Note that unlike Compile,
+// it returns the batch even when it contains exactly one error.
+func (eb *ErrBatch) ErrorOrNil() error {
+	if eb == nil || len(eb.entries) == 0 {
+		return nil
+	}
+	return eb
+}
+
+// CompileStd compiles the batch into standard library error types only.
+//
+// If the batch contains zero errors, it will return nil.
+//
+// If the batch contains exactly one error,
+// that underlying error will be returned.
+//
+// Otherwise, the underlying errors will be joined by errors.Join.
+//
+// Unlike Compile, it never returns an ErrBatch,
+// but the returned error also does not carry any ErrBatch features
+// (e.g. header or verbose formatting).
+//
+// Like Compile, it delivers the result to the Notifier set by WithNotifier.
+func (eb *ErrBatch) CompileStd() error {
+	countCompiled()
+	switch len(eb.entries) {
+	case 0:
+		return eb.notify(nil)
+	case 1:
+		return eb.notify(eb.entries[0].err)
+	default:
+		return eb.notify(errors.Join(eb.GetErrors()...))
+	}
+}
+
 // Clear clears the batch.
 func (eb *ErrBatch) Clear() {
-	eb.errors = make([]error, 0)
+	eb.entries = make([]entry, 0)
+	eb.dropped = 0
+	eb.skipped = 0
+	eb.ret = nil
 }
 
 // GetErrors returns a copy of the underlying error(s).
 func (eb *ErrBatch) GetErrors() []error {
-	errors := make([]error, len(eb.errors))
-	copy(errors, eb.errors)
+	errors := make([]error, len(eb.entries))
+	for i, e := range eb.entries {
+		errors[i] = e.err
+	}
 	return errors
 }
+
+func (eb *ErrBatch) copyEntries() []entry {
+	entries := make([]entry, len(eb.entries))
+	copy(entries, eb.entries)
+	return entries
+}
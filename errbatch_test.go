@@ -1,11 +1,16 @@
 package errbatch_test
 
 import (
+	"encoding/xml"
 	"errors"
 	"fmt"
+	"io"
 	"reflect"
+	"strings"
 	"testing"
 
+	"golang.org/x/xerrors"
+
 	"github.com/fishy/errbatch"
 )
 
@@ -31,7 +36,7 @@ func TestAdd(t *testing.T) {
 	}
 
 	var another errbatch.ErrBatch
-	err.Add(another)
+	err.Add(&another)
 	if len(err.GetErrors()) != 1 {
 		t.Error("Empty batch should be skipped.")
 	}
@@ -39,7 +44,7 @@ func TestAdd(t *testing.T) {
 	another.Add(err1)
 	err2 := errors.New("foobar")
 	another.Add(err2)
-	err.Add(another)
+	err.Add(&another)
 	if len(err.GetErrors()) != 3 {
 		t.Error("The underlying errors should be added instead of the batch.")
 	}
@@ -75,6 +80,96 @@ func TestAdd(t *testing.T) {
 	}
 }
 
+func TestWith(t *testing.T) {
+	err0 := errors.New("foo")
+	err1 := errors.New("bar")
+	batch := errbatch.New()
+	if actual := batch.With(err0).With(nil).With(err1); actual != batch {
+		t.Errorf("Expected With to return the receiver %p, got %p", batch, actual)
+	}
+	expected := []error{err0, err1}
+	if actual := batch.GetErrors(); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %#v, got %#v", expected, actual)
+	}
+
+	if err := errbatch.New().With(nil).Compile(); err != nil {
+		t.Errorf("Expected nil, got %#v", err)
+	}
+}
+
+func TestAddCall(t *testing.T) {
+	err0 := errors.New("foo")
+	err1 := errors.New("bar")
+	var batch errbatch.ErrBatch
+	func() {
+		defer batch.AddCall(func() error { return err1 })
+		defer batch.AddCall(nil)
+		defer batch.AddCall(func() error { return nil })
+		batch.Add(err0)
+	}()
+
+	expected := []error{err0, err1}
+	if actual := batch.GetErrors(); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %#v, got %#v", expected, actual)
+	}
+}
+
+// foreignBatch mimics batcherror.BatchError from baseplate.go.
+type foreignBatch struct {
+	errs []error
+}
+
+func (b foreignBatch) Error() string {
+	return fmt.Sprintf("%d errors", len(b.errs))
+}
+
+func (b foreignBatch) GetErrors() []error {
+	return b.errs
+}
+
+func TestAddForeignBatch(t *testing.T) {
+	err0 := errors.New("foo")
+	err1 := errors.New("bar")
+	err2 := errors.New("foobar")
+	var inner errbatch.ErrBatch
+	inner.Add(err2)
+
+	var batch errbatch.ErrBatch
+	batch.Add(foreignBatch{errs: []error{err0, nil, err1, &inner}})
+	expected := []error{err0, err1, err2}
+	actual := batch.GetErrors()
+	if len(actual) != len(expected) {
+		t.Fatalf("Expected %#v, got %#v", expected, actual)
+	}
+	for i := range expected {
+		if actual[i] != expected[i] {
+			t.Errorf("#%d: Expected %#v, got %#v", i, expected[i], actual[i])
+		}
+	}
+
+	batch.Clear()
+	batch.Add(fmt.Errorf("wrapped: %w", foreignBatch{errs: []error{err0}}))
+	if actual := batch.GetErrors(); len(actual) != 1 || actual[0] != err0 {
+		t.Errorf("Expected wrapped foreign batch to be flattened, got %#v", actual)
+	}
+}
+
+func TestAddJoinedBatch(t *testing.T) {
+	err0 := errors.New("foo")
+	var inner errbatch.ErrBatch
+	inner.Add(errors.New("bar"))
+	inner.Add(errors.New("foobar"))
+	err := errors.Join(err0, &inner)
+
+	// The batch inside errors.Join is not mistaken for err itself,
+	// which would drop err0.
+	var batch errbatch.ErrBatch
+	batch.Add(err)
+	if actual := batch.GetErrors(); len(actual) != 1 || actual[0] != err {
+		t.Errorf("Expected joined batch to be added as-is, got %#v", actual)
+	}
+}
+
 func TestCompile(t *testing.T) {
 	var batch errbatch.ErrBatch
 	err0 := errors.New("foo")
@@ -97,10 +192,8 @@ func TestCompile(t *testing.T) {
 	batch.Add(err1)
 	batch.Add(err2)
 	err = batch.Compile()
+	errbatch.AssertMessages(t, err, []string{"foo", "bar", "foobar"})
 	expect := "errbatch: total 3 error(s) in this batch: foo; bar; foobar"
-	if err.Error() != expect {
-		t.Errorf("Compiled error expected %#v, got %#v", expect, err)
-	}
 
 	errString := batch.Error()
 	if errString != expect {
@@ -108,6 +201,127 @@ func TestCompile(t *testing.T) {
 	}
 }
 
+type ptrError struct {
+	msg string
+}
+
+func (e *ptrError) Error() string {
+	return e.msg
+}
+
+func TestErrorTypedNil(t *testing.T) {
+	var batch errbatch.ErrBatch
+	batch.Add(errors.New("foo"))
+	batch.Add((*ptrError)(nil))
+
+	expected := "errbatch: total 2 error(s) in this batch: foo; <nil>"
+	if actual := batch.Error(); actual != expected {
+		t.Errorf("Expected %q, got %q", expected, actual)
+	}
+
+	for _, c := range []struct {
+		label  string
+		render func() error
+	}{
+		{
+			label: "MarshalJSON",
+			render: func() error {
+				_, err := batch.MarshalJSON()
+				return err
+			},
+		},
+		{
+			label: "Logfmt",
+			render: func() error {
+				batch.Logfmt()
+				return nil
+			},
+		},
+		{
+			label: "Fields",
+			render: func() error {
+				batch.Fields()
+				return nil
+			},
+		},
+		{
+			label: "Records",
+			render: func() error {
+				batch.Records()
+				return nil
+			},
+		},
+		{
+			label: "WriteJSONL",
+			render: func() error {
+				return batch.WriteJSONL(io.Discard)
+			},
+		},
+		{
+			label: "MarshalYAML",
+			render: func() error {
+				_, err := batch.MarshalYAML()
+				return err
+			},
+		},
+		{
+			label: "MarshalXML",
+			render: func() error {
+				_, err := xml.Marshal(&batch)
+				return err
+			},
+		},
+		{
+			label: "MarshalBinary",
+			render: func() error {
+				_, err := batch.MarshalBinary()
+				return err
+			},
+		},
+		{
+			label: "MarshalCBOR",
+			render: func() error {
+				_, err := batch.MarshalCBOR()
+				return err
+			},
+		},
+		{
+			label: "MarshalMsgpack",
+			render: func() error {
+				_, err := batch.MarshalMsgpack()
+				return err
+			},
+		},
+		{
+			label: "NormalizedString",
+			render: func() error {
+				batch.NormalizedString()
+				return nil
+			},
+		},
+		{
+			label: "Canonicalize",
+			render: func() error {
+				batch.Canonicalize()
+				return nil
+			},
+		},
+		{
+			label: "SnapshotString",
+			render: func() error {
+				batch.SnapshotString()
+				return nil
+			},
+		},
+	} {
+		t.Run(c.label, func(t *testing.T) {
+			if err := c.render(); err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+		})
+	}
+}
+
 func TestGetErrors(t *testing.T) {
 	var batch errbatch.ErrBatch
 	err0 := errors.New("foo")
@@ -132,12 +346,12 @@ func TestUnwrap(t *testing.T) {
 	if err := batch.Unwrap(); err != nil {
 		t.Errorf("Unwrap on empty batch expected nil, got %v", err)
 	}
-	if errors.Is(batch, expected) {
+	if errors.Is(&batch, expected) {
 		t.Errorf("errors.Is on empty batch expected false, got true")
 	}
 
 	batch.Add(err0)
-	if !errors.Is(batch, expected) {
+	if !errors.Is(&batch, expected) {
 		t.Errorf("errors.Is on one item batch expected true, got false")
 	}
 
@@ -145,7 +359,278 @@ func TestUnwrap(t *testing.T) {
 	if err := batch.Unwrap(); err != nil {
 		t.Errorf("Unwrap on more-than-one batch expected nil, got %v", err)
 	}
-	if errors.Is(batch, expected) {
+	if errors.Is(&batch, expected) {
 		t.Errorf("errors.Is on more-than-one batch expected false, got true")
 	}
 }
+
+func TestIs(t *testing.T) {
+	err0 := errors.New("foo")
+	err1 := errors.New("bar")
+	wrapped0 := fmt.Errorf("wrapped: %w", err0)
+
+	var batchA, batchB, batchC errbatch.ErrBatch
+	if !errors.Is(&batchA, &batchB) {
+		t.Error("Expected two empty batches to match")
+	}
+
+	batchA.Add(wrapped0)
+	batchA.Add(err1)
+	batchB.Add(err0)
+	batchB.Add(err1)
+	if !errors.Is(&batchA, &batchB) {
+		t.Errorf("Expected %v to match %v", &batchA, &batchB)
+	}
+	if errors.Is(&batchB, &batchA) {
+		t.Errorf("Expected %v to not match %v", &batchB, &batchA)
+	}
+
+	batchC.Add(err1)
+	batchC.Add(err0)
+	if errors.Is(&batchA, &batchC) {
+		t.Errorf("Expected %v to not match %v (different order)", &batchA, &batchC)
+	}
+	batchC.Clear()
+	batchC.Add(err0)
+	if errors.Is(&batchA, &batchC) {
+		t.Errorf("Expected %v to not match %v (different length)", &batchA, &batchC)
+	}
+
+	if !errors.Is(fmt.Errorf("wrapped: %w", &batchA), &batchB) {
+		t.Errorf("Expected wrapped %v to match %v", &batchA, &batchB)
+	}
+	var nilBatch *errbatch.ErrBatch
+	if errors.Is(&batchA, nilBatch) {
+		t.Errorf("Expected %v to not match nil pointer", &batchA)
+	}
+}
+
+type embeddingError struct {
+	errbatch.ErrBatch
+
+	label string
+}
+
+func TestAs(t *testing.T) {
+	var batch errbatch.ErrBatch
+	err0 := errors.New("foo")
+	err1 := errors.New("bar")
+	batch.Add(err0)
+	batch.Add(err1)
+	expected := []error{err0, err1}
+
+	for _, c := range []struct {
+		label string
+		err   error
+	}{
+		{
+			label: "pointer",
+			err:   &batch,
+		},
+		{
+			label: "wrapped",
+			err:   fmt.Errorf("wrapped: %w", &batch),
+		},
+	} {
+		t.Run(c.label, func(t *testing.T) {
+			t.Run("pointer", func(t *testing.T) {
+				var target *errbatch.ErrBatch
+				if !errors.As(c.err, &target) {
+					t.Fatalf("errors.As(%v, **ErrBatch) returned false", c.err)
+				}
+				if errs := target.GetErrors(); !reflect.DeepEqual(errs, expected) {
+					t.Errorf("Expected %#v, got %#v", expected, errs)
+				}
+			})
+
+			t.Run("embedded", func(t *testing.T) {
+				target := &embeddingError{label: "label"}
+				if !errors.As(c.err, &target) {
+					t.Fatalf("errors.As(%v, **embeddingError) returned false", c.err)
+				}
+				if errs := target.GetErrors(); !reflect.DeepEqual(errs, expected) {
+					t.Errorf("Expected %#v, got %#v", expected, errs)
+				}
+				if target.label != "label" {
+					t.Errorf("Expected label to be kept, got %q", target.label)
+				}
+			})
+
+			t.Run("embedded-nil", func(t *testing.T) {
+				var target *embeddingError
+				if !errors.As(c.err, &target) {
+					t.Fatalf("errors.As(%v, **embeddingError) returned false", c.err)
+				}
+				if errs := target.GetErrors(); !reflect.DeepEqual(errs, expected) {
+					t.Errorf("Expected %#v, got %#v", expected, errs)
+				}
+			})
+		})
+	}
+}
+
+func TestAsKeepsState(t *testing.T) {
+	batch := errbatch.New(errbatch.WithIgnore(io.EOF))
+	batch.Add(errors.New("foo"))
+	batch.Add(io.EOF)
+	if err := batch.SetTraceparent(traceparent); err != nil {
+		t.Fatal(err)
+	}
+
+	var ptr *errbatch.ErrBatch
+	if !errors.As(batch, &ptr) {
+		t.Fatal("errors.As(**ErrBatch) returned false")
+	}
+	embedded := &embeddingError{label: "label"}
+	if !errors.As(batch, &embedded) {
+		t.Fatal("errors.As(**embeddingError) returned false")
+	}
+
+	for _, target := range []*errbatch.ErrBatch{ptr, &embedded.ErrBatch} {
+		if actual := target.Traceparent(); actual != traceparent {
+			t.Errorf("Expected traceparent %q, got %q", traceparent, actual)
+		}
+		if actual := target.Skipped(); actual != 1 {
+			t.Errorf("Expected 1 skipped, got %d", actual)
+		}
+		target.Add(io.EOF)
+		if actual := len(target.GetErrors()); actual != 1 {
+			t.Errorf("Expected options to be kept with 1 error, got %d", actual)
+		}
+	}
+}
+
+func TestFormatError(t *testing.T) {
+	var batch errbatch.ErrBatch
+	batch.Add(xerrors.New("foo"))
+	batch.Add(errors.New("bar"))
+	wrapped := xerrors.Errorf("wrapped: %w", &batch)
+
+	expected := "wrapped: errbatch: total 2 error(s) in this batch: foo; bar"
+	if actual := fmt.Sprintf("%v", wrapped); actual != expected {
+		t.Errorf("Expected %q, got %q", expected, actual)
+	}
+
+	actual := fmt.Sprintf("%+v", wrapped)
+	for _, substr := range []string{
+		"errbatch: total 2 error(s) in this batch: foo; bar:\n",
+		"\n    foo:\n",
+		"errbatch_test.go:",
+		"\n    bar",
+	} {
+		if !strings.Contains(actual, substr) {
+			t.Errorf("Expected %q in %q", substr, actual)
+		}
+	}
+}
+
+func TestTryCompile(t *testing.T) {
+	var batch errbatch.ErrBatch
+	if err, ok := batch.TryCompile(); err != nil || ok {
+		t.Errorf("Expected (nil, false), got (%#v, %v)", err, ok)
+	}
+
+	err0 := errors.New("foo")
+	batch.Add(err0)
+	if err, ok := batch.TryCompile(); err != err0 || !ok {
+		t.Errorf("Expected (%#v, true), got (%#v, %v)", err0, err, ok)
+	}
+
+	batch.Add(errors.New("bar"))
+	if err, ok := batch.TryCompile(); err != &batch || !ok {
+		t.Errorf("Expected (%#v, true), got (%#v, %v)", &batch, err, ok)
+	}
+}
+
+func TestWithNoSingleUnwrap(t *testing.T) {
+	batch := errbatch.New(errbatch.WithNoSingleUnwrap())
+	if err := batch.Compile(); err != nil {
+		t.Errorf("Expected nil for empty batch, got %#v", err)
+	}
+
+	batch.Add(errors.New("foo"))
+	if err := batch.Compile(); err != batch {
+		t.Errorf("Expected %#v, got %#v", batch, err)
+	}
+	if _, ok := batch.CompileValue().(errbatch.CompiledBatch); !ok {
+		t.Errorf("Expected CompiledBatch, got %#v", batch.CompileValue())
+	}
+}
+
+func TestErrorOrNil(t *testing.T) {
+	var nilBatch *errbatch.ErrBatch
+	if err := nilBatch.ErrorOrNil(); err != nil {
+		t.Errorf("Expected nil for nil batch, got %#v", err)
+	}
+
+	var batch errbatch.ErrBatch
+	if err := batch.ErrorOrNil(); err != nil {
+		t.Errorf("Expected nil for empty batch, got %#v", err)
+	}
+
+	batch.Add(errors.New("foo"))
+	if err := batch.ErrorOrNil(); err != &batch {
+		t.Errorf("Expected the batch itself, got %#v", err)
+	}
+}
+
+func TestCompileStd(t *testing.T) {
+	var batch errbatch.ErrBatch
+	err0 := errors.New("foo")
+	err1 := errors.New("bar")
+
+	if err := batch.CompileStd(); err != nil {
+		t.Errorf("An empty batch should be compiled to nil, got: %#v", err)
+	}
+	batch.Add(err0)
+	if err := batch.CompileStd(); err != err0 {
+		t.Errorf(
+			"A single error batch should be compiled to %#v, got %#v",
+			err0,
+			err,
+		)
+	}
+	batch.Add(err1)
+	err := batch.CompileStd()
+	if errors.As(err, new(*errbatch.ErrBatch)) {
+		t.Errorf("Expected %#v to not be an ErrBatch", err)
+	}
+	if !errors.Is(err, err0) || !errors.Is(err, err1) {
+		t.Errorf("Expected %#v to match both %v and %v", err, err0, err1)
+	}
+	expect := "foo\nbar"
+	if err.Error() != expect {
+		t.Errorf("Compiled error expected %q, got %q", expect, err.Error())
+	}
+}
+
+func BenchmarkError(b *testing.B) {
+	for _, n := range []int{10, 100, 1000} {
+		b.Run(fmt.Sprintf("%d", n), func(b *testing.B) {
+			var batch errbatch.ErrBatch
+			for i := 0; i < n; i++ {
+				batch.Add(fmt.Errorf("error #%d", i))
+			}
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				_ = batch.Error()
+			}
+		})
+	}
+}
+
+func BenchmarkAdd(b *testing.B) {
+	err := errors.New("foo")
+	for _, n := range []int{10, 100, 1000} {
+		b.Run(fmt.Sprintf("%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				var batch errbatch.ErrBatch
+				for j := 0; j < n; j++ {
+					batch.Add(err)
+				}
+			}
+		})
+	}
+}
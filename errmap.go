@@ -0,0 +1,80 @@
+package errbatch
+
+import (
+	"fmt"
+)
+
+// ErrMap is a collection of errors keyed by K.
+//
+// It's useful for workloads keyed by IDs (e.g. user IDs, shard numbers),
+// where the key is critical context of the error.
+//
+// The zero value of ErrMap is valid (with no errors) and ready to use.
+//
+// Like ErrBatch, ErrMap is not thread-safe.
+type ErrMap[K comparable] struct {
+	keys []K
+	errs map[K]error
+}
+
+// Set sets the error for key k, replacing the existing one if any.
+//
+// Setting a nil error removes the existing error for k.
+func (em *ErrMap[K]) Set(k K, err error) {
+	if err == nil {
+		em.Delete(k)
+		return
+	}
+
+	if em.errs == nil {
+		em.errs = make(map[K]error)
+	}
+	if _, ok := em.errs[k]; !ok {
+		em.keys = append(em.keys, k)
+	}
+	em.errs[k] = err
+}
+
+// Delete removes the error for key k.
+func (em *ErrMap[K]) Delete(k K) {
+	if _, ok := em.errs[k]; !ok {
+		return
+	}
+	delete(em.errs, k)
+	for i, key := range em.keys {
+		if key == k {
+			em.keys = append(em.keys[:i], em.keys[i+1:]...)
+			break
+		}
+	}
+}
+
+// Get returns the error for key k, or nil if there's none.
+func (em *ErrMap[K]) Get(k K) error {
+	return em.errs[k]
+}
+
+// Len returns the number of keys with errors.
+func (em *ErrMap[K]) Len() int {
+	return len(em.keys)
+}
+
+// Keys returns the keys with errors, in the order they were first set.
+func (em *ErrMap[K]) Keys() []K {
+	keys := make([]K, len(em.keys))
+	copy(keys, em.keys)
+	return keys
+}
+
+// Compile compiles the errors into a batch and compiles the batch.
+//
+// Each error is added as if via AddNamed,
+// with fmt.Sprint(k) as the name, in the order returned by Keys.
+// See ErrBatch.Compile for details of the returned error.
+func (em *ErrMap[K]) Compile() error {
+	var batch ErrBatch
+	for _, k := range em.keys {
+		batch.AddNamed(fmt.Sprint(k), em.errs[k])
+	}
+	return batch.Compile()
+}
@@ -0,0 +1,49 @@
+package errbatch_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/fishy/errbatch"
+)
+
+func TestErrMap(t *testing.T) {
+	var em errbatch.ErrMap[int]
+	if err := em.Compile(); err != nil {
+		t.Errorf("An empty ErrMap should be compiled to nil, got: %#v", err)
+	}
+
+	err0 := errors.New("foo")
+	err1 := errors.New("bar")
+	em.Set(42, err0)
+	em.Set(7, err1)
+	em.Set(100, nil)
+	if em.Len() != 2 {
+		t.Errorf("Expected Len 2, got %d", em.Len())
+	}
+	if err := em.Get(42); err != err0 {
+		t.Errorf("Expected Get(42) to be %v, got %v", err0, err)
+	}
+	if err := em.Get(100); err != nil {
+		t.Errorf("Expected Get(100) to be nil, got %v", err)
+	}
+	if keys, expected := em.Keys(), []int{42, 7}; !reflect.DeepEqual(keys, expected) {
+		t.Errorf("Expected keys %v, got %v", expected, keys)
+	}
+
+	expected := "errbatch: total 2 error(s) in this batch: 42: foo; 7: bar"
+	if err := em.Compile(); err.Error() != expected {
+		t.Errorf("Expected %q, got %q", expected, err.Error())
+	}
+
+	em.Set(42, nil)
+	err := em.Compile()
+	expected = "7: bar"
+	if err.Error() != expected {
+		t.Errorf("Expected %q, got %q", expected, err.Error())
+	}
+	if !errors.Is(err, err1) {
+		t.Errorf("Expected %v to wrap %v", err, err1)
+	}
+}
@@ -0,0 +1,57 @@
+package errbatch
+
+import (
+	"fmt"
+)
+
+// Make sure *formattedError satisfies error interface.
+var _ error = (*formattedError)(nil)
+
+// formattedError is an error with a custom message wrapping multiple errors.
+type formattedError struct {
+	msg  string
+	errs []error
+}
+
+// Errorf formats according to a format specifier and returns an error
+// wrapping all the errors from %w verbs.
+//
+// The error message is the same as fmt.Errorf.
+// Nil errors from %w verbs are skipped,
+// and errors.Is and errors.As work on each of the errors from %w verbs.
+//
+// Unlike batches, the returned error is added into a batch as a single error,
+// so the formatted message is kept.
+//
+// Example:
+//
+//	return errbatch.Errorf("failed to sync %q: %w; %w", name, readErr, writeErr)
+func Errorf(format string, args ...any) error {
+	err := fmt.Errorf(format, args...)
+	fe := &formattedError{
+		msg: err.Error(),
+	}
+	switch u := err.(type) {
+	case interface{ Unwrap() []error }:
+		for _, e := range u.Unwrap() {
+			if e != nil {
+				fe.errs = append(fe.errs, e)
+			}
+		}
+	case interface{ Unwrap() error }:
+		if e := u.Unwrap(); e != nil {
+			fe.errs = append(fe.errs, e)
+		}
+	}
+	return fe
+}
+
+func (fe *formattedError) Error() string {
+	return fe.msg
+}
+
+// Unwrap returns the errors from %w verbs,
+// so that errors.Is and errors.As can check each of them.
+func (fe *formattedError) Unwrap() []error {
+	return fe.errs
+}
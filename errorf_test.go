@@ -0,0 +1,70 @@
+package errbatch_test
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/fishy/errbatch"
+)
+
+type customError struct{}
+
+func (customError) Error() string {
+	return "custom"
+}
+
+func TestErrorf(t *testing.T) {
+	err0 := errors.New("foo")
+	err1 := customError{}
+	err := errbatch.Errorf("failed %d: %w; %w; %v", 2, err0, err1, errors.New("bar"))
+
+	expected := "failed 2: foo; custom; bar"
+	if err.Error() != expected {
+		t.Errorf("Expected %q, got %q", expected, err.Error())
+	}
+	if actual := fmt.Sprintf("%v", err); actual != expected {
+		t.Errorf("Expected %q, got %q", expected, actual)
+	}
+	if !errors.Is(err, err0) {
+		t.Errorf("Expected errors.Is(%v, %v) to be true", err, err0)
+	}
+	var target customError
+	if !errors.As(err, &target) {
+		t.Errorf("Expected errors.As(%v, *customError) to be true", err)
+	}
+	expectedErrs := []error{err0, err1}
+	if errs := errors.Unwrap(err); errs != nil {
+		t.Errorf("Expected no single wrapped error, got %#v", errs)
+	}
+	var u interface{ Unwrap() []error }
+	if !errors.As(err, &u) {
+		t.Fatalf("Expected %T to implement Unwrap() []error", err)
+	}
+	if errs := u.Unwrap(); !reflect.DeepEqual(errs, expectedErrs) {
+		t.Errorf("Expected %#v, got %#v", expectedErrs, errs)
+	}
+
+	err = errbatch.Errorf("no wrap: %v", err0)
+	if !errors.As(err, &u) {
+		t.Fatalf("Expected %T to implement Unwrap() []error", err)
+	}
+	if errs := u.Unwrap(); len(errs) != 0 {
+		t.Errorf("Expected no errors without %%w, got %#v", errs)
+	}
+}
+
+func TestErrorfAdd(t *testing.T) {
+	var batch errbatch.ErrBatch
+	batch.Add(errbatch.Errorf("sync %q: %w; %w", "foo", errors.New("read"), errors.New("write")))
+	batch.Add(errors.New("bar"))
+
+	if n := len(batch.GetErrors()); n != 2 {
+		t.Errorf("Expected 2 errors, got %d", n)
+	}
+	const expected = `errbatch: total 2 error(s) in this batch: sync "foo": read; write; bar`
+	if actual := batch.Error(); actual != expected {
+		t.Errorf("Expected %q, got %q", expected, actual)
+	}
+}
@@ -0,0 +1,23 @@
+// Package expvarbatch publishes errbatch package-level counters via expvar.
+//
+// It's intended to be imported for its side effect only:
+//
+//	import _ "github.com/fishy/errbatch/expvarbatch"
+//
+// After that, the counters will be available as "errbatch" in /debug/vars.
+package expvarbatch
+
+import (
+	"expvar"
+
+	"github.com/fishy/errbatch"
+)
+
+// Name is the name the counters are published under.
+const Name = "errbatch"
+
+func init() {
+	expvar.Publish(Name, expvar.Func(func() interface{} {
+		return errbatch.GetStats()
+	}))
+}
@@ -0,0 +1,45 @@
+package expvarbatch_test
+
+import (
+	"encoding/json"
+	"errors"
+	"expvar"
+	"testing"
+
+	"github.com/fishy/errbatch"
+	"github.com/fishy/errbatch/expvarbatch"
+)
+
+func TestPublish(t *testing.T) {
+	v := expvar.Get(expvarbatch.Name)
+	if v == nil {
+		t.Fatalf("%q not published", expvarbatch.Name)
+	}
+
+	var before errbatch.Stats
+	if err := json.Unmarshal([]byte(v.String()), &before); err != nil {
+		t.Fatalf("Failed to decode %s: %v", v.String(), err)
+	}
+
+	var batch errbatch.ErrBatch
+	batch.Add(errors.New("foo"))
+	batch.Add(errors.New("bar"))
+	batch.Compile()
+	ring := errbatch.New(errbatch.WithRingBuffer(1))
+	ring.Add(errors.New("foo"))
+	ring.Add(errors.New("bar"))
+
+	var after errbatch.Stats
+	if err := json.Unmarshal([]byte(v.String()), &after); err != nil {
+		t.Fatalf("Failed to decode %s: %v", v.String(), err)
+	}
+	if diff := after.Added - before.Added; diff != 4 {
+		t.Errorf("Expected Added to increase by 4, got %d", diff)
+	}
+	if diff := after.Compiled - before.Compiled; diff != 1 {
+		t.Errorf("Expected Compiled to increase by 1, got %d", diff)
+	}
+	if diff := after.Dropped - before.Dropped; diff != 1 {
+		t.Errorf("Expected Dropped to increase by 1, got %d", diff)
+	}
+}
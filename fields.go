@@ -0,0 +1,33 @@
+package errbatch
+
+// Field keys used by Fields.
+const (
+	FieldCount    = "error_count"
+	FieldMessages = "error_messages"
+	FieldTypes    = "error_types"
+)
+
+// Fields returns the batch as fields for structured loggers (e.g. slog,
+// logrus, zap's SugaredLogger),
+// with the number of errors (FieldCount, int),
+// and the message (FieldMessages, []string) and type name
+// (FieldTypes, []string) of each of the underlying errors.
+// The messages are redacted by WithRedactor.
+//
+// Example:
+//
+//	logrus.WithFields(batch.Fields()).Error("workers failed")
+func (eb *ErrBatch) Fields() map[string]any {
+	opts := eb.getOptions()
+	messages := make([]string, len(eb.entries))
+	types := make([]string, len(eb.entries))
+	for i, e := range eb.entries {
+		messages[i] = opts.redact(errorMessage(e.err))
+		types[i] = typeName(e.err)
+	}
+	return map[string]any{
+		FieldCount:    len(eb.entries),
+		FieldMessages: messages,
+		FieldTypes:    types,
+	}
+}
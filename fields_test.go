@@ -0,0 +1,52 @@
+package errbatch_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/fishy/errbatch"
+)
+
+func TestFields(t *testing.T) {
+	for _, c := range []struct {
+		label    string
+		errs     []error
+		expected map[string]any
+	}{
+		{
+			label: "empty",
+			expected: map[string]any{
+				errbatch.FieldCount:    0,
+				errbatch.FieldMessages: []string{},
+				errbatch.FieldTypes:    []string{},
+			},
+		},
+		{
+			label: "errors",
+			errs: []error{
+				errors.New("foo"),
+				customError{},
+			},
+			expected: map[string]any{
+				errbatch.FieldCount:    2,
+				errbatch.FieldMessages: []string{"foo", "custom"},
+				errbatch.FieldTypes: []string{
+					"*errors.errorString",
+					"errbatch_test.customError",
+				},
+			},
+		},
+	} {
+		t.Run(c.label, func(t *testing.T) {
+			var batch errbatch.ErrBatch
+			for _, err := range c.errs {
+				batch.Add(err)
+			}
+			actual := batch.Fields()
+			if !reflect.DeepEqual(actual, c.expected) {
+				t.Errorf("Expected %#v, got %#v", c.expected, actual)
+			}
+		})
+	}
+}
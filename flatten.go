@@ -0,0 +1,84 @@
+package errbatch
+
+// WithRecursiveFlatten makes Add flatten nested aggregated errors
+// recursively, instead of only one level of batches.
+//
+// Besides batches recognized by Add without this option,
+// errors implementing Unwrap() []error (e.g. the ones returned by
+// errors.Join, and fmt.Errorf with multiple %w verbs),
+// and errors providing Errors() []error
+// (e.g. the ones from go.uber.org/multierr)
+// are also flattened,
+// at any depth and in any combination,
+// so the batch is always a flat list of non-aggregated errors.
+//
+// Note that the messages of the aggregated errors themselves are dropped,
+// only the messages of the errors they contain are kept.
+func WithRecursiveFlatten() Option {
+	return func(o *options) {
+		o.recursive = true
+	}
+}
+
+// WithPreserveWrapped makes Add only flatten batches added directly,
+// and keep the batches wrapped by other errors (e.g. fmt.Errorf with %w) as
+// single errors,
+// so the annotations of the wrappers are not lost.
+//
+// It also applies to the nested aggregated errors flattened by
+// WithRecursiveFlatten.
+func WithPreserveWrapped() Option {
+	return func(o *options) {
+		o.preserveWrapped = true
+	}
+}
+
+// flatten flattens err according to WithRecursiveFlatten and
+// WithPreserveWrapped.
+func (opts *options) flatten(err error, newEntry func(error) entry) ([]entry, bool) {
+	as := asChain
+	if opts.preserveWrapped {
+		as = asDirect
+	}
+	if opts.recursive {
+		return flattenRecursive(err, as, newEntry)
+	}
+	return flattenAs(err, as, newEntry)
+}
+
+// flattenRecursive is the recursive version of flattenAs.
+func flattenRecursive(
+	err error,
+	as func(err error, target any) bool,
+	newEntry func(error) entry,
+) ([]entry, bool) {
+	entries, ok := flattenAs(err, as, newEntry)
+	if !ok {
+		var errs []error
+		var aggregate interface {
+			Errors() []error
+		}
+		if u, isJoin := err.(interface{ Unwrap() []error }); isJoin {
+			errs = u.Unwrap()
+		} else if as(err, &aggregate) {
+			errs = aggregate.Errors()
+		} else {
+			return nil, false
+		}
+		for _, err := range errs {
+			if err != nil {
+				entries = append(entries, newEntry(err))
+			}
+		}
+	}
+
+	flat := make([]entry, 0, len(entries))
+	for _, e := range entries {
+		if nested, ok := flattenRecursive(e.err, as, newEntry); ok {
+			flat = append(flat, nested...)
+		} else {
+			flat = append(flat, e)
+		}
+	}
+	return flat, true
+}
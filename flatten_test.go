@@ -0,0 +1,114 @@
+package errbatch_test
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/fishy/errbatch"
+)
+
+// multiErr mimics the error type from go.uber.org/multierr.
+type multiErr []error
+
+func (m multiErr) Error() string {
+	return fmt.Sprintf("%d errors", len(m))
+}
+
+func (m multiErr) Errors() []error {
+	return m
+}
+
+func TestRecursiveFlatten(t *testing.T) {
+	err0 := errors.New("foo")
+	err1 := errors.New("bar")
+	err2 := errors.New("foobar")
+	err3 := errors.New("barfoo")
+	err4 := errors.New("baz")
+
+	var inner errbatch.ErrBatch
+	inner.Add(errors.Join(err1, multiErr{err2, nil}))
+	inner.Add(err3)
+	err := errors.Join(
+		err0,
+		fmt.Errorf("wrapped: %w", inner.Compile()),
+		foreignBatch{errs: []error{errors.Join(err4)}},
+	)
+
+	batch := errbatch.New(errbatch.WithRecursiveFlatten())
+	batch.Add(err)
+	expected := []error{err0, err1, err2, err3, err4}
+	if actual := batch.GetErrors(); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %#v, got %#v", expected, actual)
+	}
+
+	cb := errbatch.NewConcurrent(errbatch.WithRecursiveFlatten())
+	cb.Add(err)
+	if actual := cb.GetErrors(); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %#v, got %#v", expected, actual)
+	}
+
+	var plain errbatch.ErrBatch
+	plain.Add(err)
+	if actual := plain.GetErrors(); len(actual) != 1 || actual[0] != err {
+		t.Errorf("Expected errors.Join not to be flattened without the option, got %#v", actual)
+	}
+}
+
+func TestWithPreserveWrapped(t *testing.T) {
+	var inner errbatch.ErrBatch
+	inner.Add(errors.New("foo"))
+	inner.Add(errors.New("bar"))
+	wrapped := fmt.Errorf("step 1: %w", &inner)
+
+	for _, c := range []struct {
+		label    string
+		opts     []errbatch.Option
+		expected int
+	}{
+		{
+			label:    "default",
+			expected: 4,
+		},
+		{
+			label:    "preserve",
+			opts:     []errbatch.Option{errbatch.WithPreserveWrapped()},
+			expected: 3,
+		},
+		{
+			label: "preserve-recursive",
+			opts: []errbatch.Option{
+				errbatch.WithPreserveWrapped(),
+				errbatch.WithRecursiveFlatten(),
+			},
+			expected: 3,
+		},
+	} {
+		t.Run(c.label, func(t *testing.T) {
+			batch := errbatch.New(c.opts...)
+			batch.Add(wrapped)
+			batch.Add(&inner)
+			errs := batch.GetErrors()
+			if len(errs) != c.expected {
+				t.Fatalf("Expected %d errors, got %#v", c.expected, errs)
+			}
+			if c.expected == 3 && errs[0] != wrapped {
+				t.Errorf("Expected %#v, got %#v", wrapped, errs[0])
+			}
+		})
+	}
+
+	t.Run("recursive-join", func(t *testing.T) {
+		batch := errbatch.New(
+			errbatch.WithPreserveWrapped(),
+			errbatch.WithRecursiveFlatten(),
+		)
+		batch.Add(errors.Join(wrapped, errors.New("foobar")))
+		expected := []string{wrapped.Error(), "foobar"}
+		if actual := messages(batch.GetErrors()); !slices.Equal(actual, expected) {
+			t.Errorf("Expected %q, got %q", expected, actual)
+		}
+	})
+}
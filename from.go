@@ -0,0 +1,43 @@
+package errbatch
+
+import (
+	"iter"
+	"slices"
+)
+
+// FromSlice creates a new batch containing errs.
+//
+// It's equivalent to calling Add on each of errs in order,
+// so nil errors are skipped and ErrBatch errors are flattened.
+func FromSlice(errs []error) *ErrBatch {
+	batch := new(ErrBatch)
+	for _, err := range errs {
+		batch.Add(err)
+	}
+	return batch
+}
+
+// FromSeq creates a new batch containing the errors produced by seq.
+//
+// It's equivalent to calling AddFromSeq on a new batch.
+func FromSeq(seq iter.Seq[error]) *ErrBatch {
+	batch := new(ErrBatch)
+	for _, err := range slices.Collect(seq) {
+		batch.add(err, nil)
+	}
+	return batch
+}
+
+// AddFromSeq drains seq and adds all the errors it produces into the batch.
+//
+// It's equivalent to calling Add on each of the errors in order,
+// so nil errors are skipped and ErrBatch errors are flattened.
+// The call sites captured by WithCaller and WithStack point to the caller of
+// AddFromSeq.
+func (eb *ErrBatch) AddFromSeq(seq iter.Seq[error]) {
+	// Drain seq first so that add is called directly from here,
+	// instead of from the loop body called by seq.
+	for _, err := range slices.Collect(seq) {
+		eb.add(err, nil)
+	}
+}
@@ -0,0 +1,74 @@
+package errbatch_test
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime"
+	"slices"
+	"testing"
+
+	"github.com/fishy/errbatch"
+)
+
+func TestFromSlice(t *testing.T) {
+	err0 := errors.New("foo")
+	err1 := errors.New("bar")
+	err2 := errors.New("foobar")
+	var nested errbatch.ErrBatch
+	nested.Add(err1)
+	nested.Add(err2)
+
+	batch := errbatch.FromSlice([]error{nil, err0, nil, &nested})
+	expected := []error{err0, err1, err2}
+	if errs := batch.GetErrors(); !reflect.DeepEqual(errs, expected) {
+		t.Errorf("Expected %#v, got %#v", expected, errs)
+	}
+
+	if err := errbatch.FromSlice(nil).Compile(); err != nil {
+		t.Errorf("Expected nil slice to compile to nil, got %#v", err)
+	}
+}
+
+func TestFromSeq(t *testing.T) {
+	err0 := errors.New("foo")
+	err1 := errors.New("bar")
+	err2 := errors.New("foobar")
+	var nested errbatch.ErrBatch
+	nested.Add(err1)
+	nested.Add(err2)
+
+	batch := errbatch.FromSeq(slices.Values([]error{nil, err0, nil, &nested}))
+	expected := []error{err0, err1, err2}
+	if errs := batch.GetErrors(); !reflect.DeepEqual(errs, expected) {
+		t.Errorf("Expected %#v, got %#v", expected, errs)
+	}
+
+	batch.AddFromSeq(func(yield func(error) bool) {
+		for _, err := range []error{err0, err1} {
+			if !yield(err) {
+				return
+			}
+		}
+	})
+	if actual := len(batch.GetErrors()); actual != 5 {
+		t.Errorf("Expected 5 errors, got %d", actual)
+	}
+}
+
+func TestAddFromSeqCaller(t *testing.T) {
+	seq := func(yield func(error) bool) {
+		yield(errors.New("foo"))
+	}
+	batch := errbatch.New(errbatch.WithCaller())
+	batch.AddFromSeq(seq)
+	_, _, line, _ := runtime.Caller(0)
+
+	expected := fmt.Sprintf(
+		"errbatch: total 1 error(s) in this batch:\nfrom_test.go:%d: foo",
+		line-1,
+	)
+	if actual := fmt.Sprintf("%+v", batch); actual != expected {
+		t.Errorf("Expected %q, got %q", expected, actual)
+	}
+}
@@ -1,3 +1,23 @@
 module github.com/fishy/errbatch
 
-go 1.13
+go 1.23
+
+require (
+	github.com/apache/thrift v0.22.0
+	github.com/pkg/errors v0.9.1
+	github.com/rs/zerolog v1.35.1
+	github.com/sirupsen/logrus v1.9.3
+	github.com/twitchtv/twirp v8.1.3+incompatible
+	golang.org/x/xerrors v0.0.0-20240903120638-7835f813f4da
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20250218202821-56aae31c358a
+	google.golang.org/grpc v1.72.2
+	google.golang.org/protobuf v1.36.5
+)
+
+require (
+	github.com/mattn/go-colorable v0.1.14 // indirect
+	github.com/mattn/go-isatty v0.0.20 // indirect
+	golang.org/x/net v0.35.0 // indirect
+	golang.org/x/sys v0.30.0 // indirect
+	golang.org/x/text v0.22.0 // indirect
+)
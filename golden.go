@@ -0,0 +1,38 @@
+package errbatch
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// SnapshotString returns a canonical multi-line rendering of the batch
+// intended for golden files.
+//
+// The first line is the number of errors in the batch,
+// followed by one line per error in the form of "type: message",
+// sorted lexicographically.
+// Lines other than the first of multi-line messages are indented by a tab.
+//
+// Unlike Error, it does not depend on the order the errors were added in,
+// nor on the display options (e.g. WithSeparator and WithRedactor),
+// so it's stable across goroutine scheduling,
+// as long as the underlying errors are.
+func (eb *ErrBatch) SnapshotString() string {
+	lines := make([]string, len(eb.entries))
+	for i, e := range eb.entries {
+		msg := strings.ReplaceAll(errorMessage(e.err), "\n", "\n\t")
+		lines[i] = typeName(e.err) + ": " + msg
+	}
+	slices.Sort(lines)
+	header := fmt.Sprintf("%d error(s)", len(lines))
+	return strings.Join(append([]string{header}, lines...), "\n")
+}
+
+// SnapshotString returns the canonical multi-line rendering of the current
+// snapshot of the batch.
+//
+// See ErrBatch.SnapshotString for details.
+func (cb *ConcurrentErrBatch) SnapshotString() string {
+	return cb.Snapshot().SnapshotString()
+}
@@ -0,0 +1,51 @@
+package errbatch_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/fishy/errbatch"
+)
+
+func TestSnapshotString(t *testing.T) {
+	for _, c := range []struct {
+		label    string
+		errs     []error
+		expected string
+	}{
+		{
+			label:    "empty",
+			expected: "0 error(s)",
+		},
+		{
+			label: "sorted",
+			errs: []error{
+				errors.New("foo"),
+				customError{},
+				errors.New("bar\nbaz"),
+			},
+			expected: "3 error(s)\n" +
+				"*errors.errorString: bar\n\tbaz\n" +
+				"*errors.errorString: foo\n" +
+				"errbatch_test.customError: custom",
+		},
+	} {
+		t.Run(c.label, func(t *testing.T) {
+			batch := errbatch.New(errbatch.WithSeparator(", "))
+			for _, err := range c.errs {
+				batch.Add(err)
+			}
+			if actual := batch.SnapshotString(); actual != c.expected {
+				t.Errorf("Expected %q, got %q", c.expected, actual)
+			}
+
+			reversed := errbatch.NewConcurrent()
+			for i := len(c.errs) - 1; i >= 0; i-- {
+				reversed.Add(c.errs[i])
+			}
+			if actual := reversed.SnapshotString(); actual != c.expected {
+				t.Errorf("Expected %q, got %q", c.expected, actual)
+			}
+		})
+	}
+}
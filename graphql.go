@@ -0,0 +1,70 @@
+package errbatch
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// GraphQLError is an error in the "errors" array of a GraphQL response.
+//
+// It's shaped like gqlerror.Error from github.com/vektah/gqlparser/v2,
+// so it encodes to JSON as defined by the GraphQL spec.
+type GraphQLError struct {
+	Message    string         `json:"message"`
+	Path       []any          `json:"path,omitempty"`
+	Extensions map[string]any `json:"extensions,omitempty"`
+}
+
+// Error implements error.
+func (ge GraphQLError) Error() string {
+	return ge.Message
+}
+
+// GraphQLErrors converts the batch into GraphQL errors,
+// one for each of its underlying error(s).
+//
+// For errors added via AddNamed,
+// the name is split by "." into Path
+// (with numeric segments as list indices),
+// and Message is the original message without the name prefix.
+// For example, "user.friends.0.name" becomes ["user", "friends", 0, "name"].
+//
+// Errors carrying codes (see AddCoded) have the first code in
+// Extensions["code"].
+func (eb *ErrBatch) GraphQLErrors() []GraphQLError {
+	if len(eb.entries) == 0 {
+		return nil
+	}
+	ges := make([]GraphQLError, 0, len(eb.entries))
+	for _, e := range eb.entries {
+		ge := GraphQLError{
+			Message: errorMessage(e.err),
+		}
+		var ne *namedError
+		if errors.As(e.err, &ne) {
+			ge.Message = errorMessage(ne.err)
+			ge.Path = graphQLPath(ne.name)
+		}
+		if codes := Codes(e.err); len(codes) > 0 {
+			ge.Extensions = map[string]any{
+				"code": codes[0],
+			}
+		}
+		ges = append(ges, ge)
+	}
+	return ges
+}
+
+func graphQLPath(name string) []any {
+	segments := strings.Split(name, ".")
+	path := make([]any, 0, len(segments))
+	for _, s := range segments {
+		if i, err := strconv.Atoi(s); err == nil {
+			path = append(path, i)
+		} else {
+			path = append(path, s)
+		}
+	}
+	return path
+}
@@ -0,0 +1,33 @@
+package errbatch_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/fishy/errbatch"
+)
+
+func TestGraphQLErrors(t *testing.T) {
+	var batch errbatch.ErrBatch
+	if ges := batch.GraphQLErrors(); ges != nil {
+		t.Errorf("Expected nil for empty batch, got %#v", ges)
+	}
+
+	batch.Add(errors.New("foo"))
+	batch.AddNamed("user.friends.0.name", errors.New("bar"))
+	batch.AddCoded("NOT_FOUND", errors.New("foobar"))
+
+	buf, err := json.Marshal(batch.GraphQLErrors())
+	if err != nil {
+		t.Fatal(err)
+	}
+	const expected = `[` +
+		`{"message":"foo"},` +
+		`{"message":"bar","path":["user","friends",0,"name"]},` +
+		`{"message":"foobar","extensions":{"code":"NOT_FOUND"}}` +
+		`]`
+	if string(buf) != expected {
+		t.Errorf("Expected %s, got %s", expected, buf)
+	}
+}
@@ -0,0 +1,146 @@
+package errbatch
+
+import (
+	"context"
+	"sync"
+)
+
+// Group runs functions in goroutines and collects all their errors.
+//
+// Unlike golang.org/x/sync/errgroup, which only keeps the first error,
+// Wait returns all the errors returned by the functions as a batch.
+//
+// The zero value of Group is valid and ready to use.
+// A Group must not be copied after first use.
+type Group struct {
+	wg   sync.WaitGroup
+	errs ConcurrentErrBatch
+
+	// cancel of the context returned by GroupWithContext, nil otherwise.
+	cancel context.CancelCauseFunc
+}
+
+// NewGroup creates a new Group with the given options,
+// which are used by the batch returned by Wait.
+func NewGroup(opts ...Option) *Group {
+	return &Group{
+		errs: ConcurrentErrBatch{
+			opts: New(opts...).opts,
+		},
+	}
+}
+
+// GroupWithContext creates a new Group the same as NewGroup,
+// and a context derived from ctx,
+// which is canceled when Wait returns,
+// or when any function started by the group returns a non-nil error if
+// WithCancelOnError is used.
+//
+// The cause of the cancellation (see context.Cause) is the first error
+// returned by the functions, or context.Canceled when Wait returns.
+func GroupWithContext(ctx context.Context, opts ...Option) (*Group, context.Context) {
+	g := NewGroup(opts...)
+	ctx, g.cancel = context.WithCancelCause(ctx)
+	return g, ctx
+}
+
+// WithCancelOnError makes the context returned by GroupWithContext canceled on
+// the first error returned by the functions of the group,
+// like golang.org/x/sync/errgroup,
+// so the other functions can stop early.
+//
+// Unlike errgroup, all the errors returned before (and after) the
+// cancellation are still collected.
+// The errors caused by the cancellation itself (context.Canceled) can be
+// skipped by WithSkipCanceled.
+// Errors skipped by WithIgnore (or WithSkipCanceled) do not cancel the
+// context.
+//
+// It has no effect on groups not created by GroupWithContext.
+func WithCancelOnError() Option {
+	return func(o *options) {
+		o.cancelOnError = true
+	}
+}
+
+// Go calls fn in a new goroutine,
+// and adds the error it returns into the group.
+func (g *Group) Go(fn func() error) {
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		err := fn()
+		g.errs.Add(err)
+		g.fail(err)
+	}()
+}
+
+// GoNamed is the same as Go,
+// but the error returned by fn is labeled with name
+// (as if added via ErrBatch.AddNamed),
+// so the compiled error identifies which worker failed, e.g.
+// "errbatch: total 2 error(s) in this batch: fetch-users: timeout; fetch-orders: EOF".
+func (g *Group) GoNamed(name string, fn func() error) {
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		err := fn()
+		g.errs.AddNamed(name, err)
+		g.fail(err)
+	}()
+}
+
+// Semaphore is the interface of a weighted semaphore used by
+// Group.GoWeighted.
+//
+// *golang.org/x/sync/semaphore.Weighted satisfies it.
+type Semaphore interface {
+	Acquire(ctx context.Context, n int64) error
+	Release(n int64)
+}
+
+// GoWeighted acquires weight from sem, then calls fn in a new goroutine the
+// same as Go, and releases weight after fn returns,
+// so tasks with different weights can share one concurrency budget,
+// while all their errors are still collected.
+//
+// It blocks until weight is acquired or ctx is done.
+// If acquiring fails, fn is not called,
+// and the error returned by sem (usually ctx.Err()) is added into the group
+// instead.
+func (g *Group) GoWeighted(ctx context.Context, sem Semaphore, weight int64, fn func() error) {
+	if err := sem.Acquire(ctx, weight); err != nil {
+		g.errs.Add(err)
+		g.fail(err)
+		return
+	}
+	g.Go(func() error {
+		defer sem.Release(weight)
+		return fn()
+	})
+}
+
+// Wait waits for all the functions started by Go, GoNamed and GoWeighted to
+// return,
+// then returns the compiled batch of their errors.
+//
+// See ErrBatch.Compile for details of the returned error.
+// The errors are in the order they were returned by the functions.
+func (g *Group) Wait() error {
+	g.wg.Wait()
+	if g.cancel != nil {
+		g.cancel(nil)
+	}
+	return g.errs.Compile()
+}
+
+// fail cancels the context of the group on err according to
+// WithCancelOnError.
+func (g *Group) fail(err error) {
+	if g.cancel == nil {
+		return
+	}
+	if opts := g.errs.getOptions(); opts.cancelOnError && !opts.skips(err) {
+		g.cancel(err)
+	}
+}
@@ -0,0 +1,202 @@
+package errbatch_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fishy/errbatch"
+)
+
+func TestGroup(t *testing.T) {
+	var group errbatch.Group
+	if err := group.Wait(); err != nil {
+		t.Errorf("Expected nil, got %#v", err)
+	}
+
+	err0 := errors.New("foo")
+	for i := 0; i < 10; i++ {
+		group.Go(func() error {
+			if i == 0 {
+				return err0
+			}
+			return nil
+		})
+	}
+	if err := group.Wait(); err != err0 {
+		t.Errorf("Expected %#v, got %#v", err0, err)
+	}
+}
+
+func TestGroupNamed(t *testing.T) {
+	group := errbatch.NewGroup(errbatch.WithHeaderMinCount(3))
+	group.GoNamed("fetch-users", func() error {
+		return errors.New("timeout")
+	})
+	group.GoNamed("fetch-orders", func() error {
+		return errors.New("EOF")
+	})
+	group.GoNamed("fetch-items", func() error {
+		return nil
+	})
+
+	err := group.Wait()
+	var batch *errbatch.ErrBatch
+	if !errors.As(err, &batch) {
+		t.Fatalf("Expected a batch, got %#v", err)
+	}
+	keys := make([]string, 0)
+	for key := range batch.ErrorsByKey() {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	if expected := []string{"fetch-orders", "fetch-users"}; !slices.Equal(keys, expected) {
+		t.Errorf("Expected keys %q, got %q", expected, keys)
+	}
+	for _, msg := range []string{"fetch-users: timeout", "fetch-orders: EOF"} {
+		if !slices.ContainsFunc(batch.GetErrors(), func(err error) bool {
+			return err.Error() == msg
+		}) {
+			t.Errorf("Expected %q in %v", msg, err)
+		}
+	}
+}
+
+func TestGroupWithContext(t *testing.T) {
+	errFoo := errors.New("foo")
+
+	t.Run("cancel-on-error", func(t *testing.T) {
+		group, ctx := errbatch.GroupWithContext(
+			context.Background(),
+			errbatch.WithCancelOnError(),
+		)
+		group.Go(func() error {
+			return errFoo
+		})
+		group.Go(func() error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+		var batch *errbatch.ErrBatch
+		if err := group.Wait(); !errors.As(err, &batch) {
+			t.Fatalf("Expected a batch, got %#v", err)
+		}
+		errs := batch.GetErrors()
+		if len(errs) != 2 || !slices.Contains(errs, errFoo) || !slices.Contains(errs, context.Canceled) {
+			t.Errorf("Expected both errors to be collected, got %v", errs)
+		}
+		if cause := context.Cause(ctx); cause != errFoo {
+			t.Errorf("Expected cause %#v, got %#v", errFoo, cause)
+		}
+	})
+
+	t.Run("no-cancel-on-error", func(t *testing.T) {
+		group, ctx := errbatch.GroupWithContext(context.Background())
+		group.Go(func() error {
+			return errFoo
+		})
+		group.Go(func() error {
+			return ctx.Err()
+		})
+		if err := group.Wait(); err != errFoo {
+			t.Errorf("Expected %#v, got %#v", errFoo, err)
+		}
+		if cause := context.Cause(ctx); cause != context.Canceled {
+			t.Errorf("Expected context canceled after Wait, got %#v", cause)
+		}
+	})
+
+	t.Run("ignored", func(t *testing.T) {
+		group, ctx := errbatch.GroupWithContext(
+			context.Background(),
+			errbatch.WithCancelOnError(),
+			errbatch.WithIgnore(errFoo),
+			errbatch.WithSkipCanceled(),
+		)
+		group.Go(func() error {
+			return errFoo
+		})
+		group.Go(func() error {
+			return context.Canceled
+		})
+		group.Go(func() error {
+			var batch errbatch.ErrBatch
+			batch.Add(errFoo)
+			batch.Add(context.Canceled)
+			return &batch
+		})
+		if err := group.Wait(); err != nil {
+			t.Errorf("Expected nil, got %#v", err)
+		}
+		if cause := context.Cause(ctx); cause != context.Canceled {
+			t.Errorf("Expected ignored errors not to cancel the context, got cause %#v", cause)
+		}
+	})
+}
+
+// chanSemaphore is a minimal weighted semaphore for tests.
+type chanSemaphore chan struct{}
+
+func (s chanSemaphore) Acquire(ctx context.Context, n int64) error {
+	for i := int64(0); i < n; i++ {
+		select {
+		case s <- struct{}{}:
+		case <-ctx.Done():
+			s.Release(i)
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+func (s chanSemaphore) Release(n int64) {
+	for i := int64(0); i < n; i++ {
+		<-s
+	}
+}
+
+func TestGroupGoWeighted(t *testing.T) {
+	const limit = 3
+	sem := make(chanSemaphore, limit)
+	var group errbatch.Group
+	var running, maxRunning atomic.Int64
+	for i := range 10 {
+		group.GoWeighted(context.Background(), sem, int64(i%2+1), func() error {
+			n := running.Add(int64(i%2 + 1))
+			defer running.Add(-int64(i%2 + 1))
+			for {
+				current := maxRunning.Load()
+				if n <= current || maxRunning.CompareAndSwap(current, n) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			if i == 5 {
+				return errors.New("foo")
+			}
+			return nil
+		})
+	}
+	if err := group.Wait(); err == nil || err.Error() != "foo" {
+		t.Errorf("Expected foo, got %v", err)
+	}
+	if actual := maxRunning.Load(); actual > limit {
+		t.Errorf("Expected at most %d weight running, got %d", limit, actual)
+	}
+
+	t.Run("canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		var group errbatch.Group
+		group.GoWeighted(ctx, make(chanSemaphore), 1, func() error {
+			t.Error("Expected fn not to be called")
+			return nil
+		})
+		if err := group.Wait(); err != context.Canceled {
+			t.Errorf("Expected %#v, got %#v", context.Canceled, err)
+		}
+	})
+}
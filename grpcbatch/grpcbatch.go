@@ -0,0 +1,178 @@
+// Package grpcbatch provides gRPC server interceptors that collect per-RPC
+// errors into errbatch.ConcurrentErrBatch.
+//
+// It's the gRPC counterpart of httpbatch.Middleware.
+package grpcbatch
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+
+	"github.com/fishy/errbatch"
+)
+
+// Config configures the interceptors.
+type Config struct {
+	// Report is called with the compiled batch when the RPC finishes,
+	// if any error was added during the RPC.
+	//
+	// If it's nil, the error will be logged via the log package.
+	Report func(ctx context.Context, method string, err error)
+
+	// If Code is not codes.OK, any error was added during the RPC,
+	// and the handler itself returned nil error,
+	// the RPC fails with Code.
+	//
+	// When it's codes.OK (the default),
+	// errors collected are only reported (and attached to the status if the
+	// handler returned an error), and do not fail the RPC.
+	Code codes.Code
+
+	// When DebugInfo is true and the RPC fails,
+	// each of the errors collected is attached to the returned status as
+	// errdetails.DebugInfo.
+	//
+	// It's off by default, as the raw messages of internal errors are sent to
+	// the clients.
+	DebugInfo bool
+
+	// When EmbedTraceparent is true and the incoming metadata of the RPC
+	// carries a valid W3C Trace Context traceparent
+	// (see errbatch.ValidTraceparent),
+	// it's also attached to the returned status as errdetails.RequestInfo
+	// (with the traceparent as RequestId),
+	// so downstream error pipelines can correlate the errors with the trace.
+	EmbedTraceparent bool
+}
+
+// traceparentKey is the metadata key of W3C Trace Context traceparent.
+const traceparentKey = "traceparent"
+
+// UnaryServerInterceptor returns a unary server interceptor with the default
+// Config.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return Config{}.UnaryServerInterceptor()
+}
+
+// StreamServerInterceptor returns a stream server interceptor with the
+// default Config.
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return Config{}.StreamServerInterceptor()
+}
+
+// UnaryServerInterceptor returns a unary server interceptor that installs an
+// errbatch.ConcurrentErrBatch into the context of every RPC.
+//
+// Handlers can add errors to it via errbatch.AddToContext or
+// errbatch.FromContext.
+// When the handler returns, the batch is compiled and reported,
+// and the status is built according to the config.
+func (cfg Config) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		batch := new(errbatch.ConcurrentErrBatch)
+		ctx = errbatch.NewContext(ctx, batch)
+		resp, err := handler(ctx, req)
+		return resp, cfg.finish(ctx, info.FullMethod, batch, err)
+	}
+}
+
+// StreamServerInterceptor returns a stream server interceptor that works the
+// same as the one returned by UnaryServerInterceptor.
+func (cfg Config) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(
+		srv interface{},
+		ss grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		batch := new(errbatch.ConcurrentErrBatch)
+		ctx := errbatch.NewContext(ss.Context(), batch)
+		err := handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+		return cfg.finish(ctx, info.FullMethod, batch, err)
+	}
+}
+
+func (cfg Config) finish(
+	ctx context.Context,
+	method string,
+	batch *errbatch.ConcurrentErrBatch,
+	err error,
+) error {
+	// Compile a single snapshot,
+	// so the status and its details agree even if errors are still being
+	// added by other goroutines.
+	snapshot := batch.Snapshot()
+	compiled := snapshot.Compile()
+	if compiled == nil {
+		return err
+	}
+	cfg.report(ctx, method, compiled)
+
+	var st *status.Status
+	switch {
+	case err != nil:
+		st = status.Convert(err)
+	case cfg.Code != codes.OK:
+		st = status.New(cfg.Code, compiled.Error())
+	default:
+		return nil
+	}
+
+	var details []protoadapt.MessageV1
+	if cfg.DebugInfo {
+		for _, e := range snapshot.GetErrors() {
+			details = append(details, &errdetails.DebugInfo{
+				Detail: e.Error(),
+			})
+		}
+	}
+	if cfg.EmbedTraceparent {
+		if tp := metadata.ValueFromIncomingContext(ctx, traceparentKey); len(tp) > 0 && errbatch.ValidTraceparent(tp[0]) {
+			details = append(details, &errdetails.RequestInfo{
+				RequestId: tp[0],
+			})
+		}
+	}
+	if len(details) > 0 {
+		withDetails, detailsErr := st.WithDetails(details...)
+		if detailsErr != nil {
+			cfg.report(ctx, method, fmt.Errorf("grpcbatch: failed to attach details: %w", detailsErr))
+		} else {
+			st = withDetails
+		}
+	}
+	return st.Err()
+}
+
+// report reports err via Report, or logs it if Report is nil.
+func (cfg Config) report(ctx context.Context, method string, err error) {
+	if cfg.Report != nil {
+		cfg.Report(ctx, method, err)
+	} else {
+		log.Printf("errbatch: %s: %v", method, err)
+	}
+}
+
+// serverStream overrides the context of the wrapped grpc.ServerStream.
+type serverStream struct {
+	grpc.ServerStream
+
+	ctx context.Context
+}
+
+func (ss *serverStream) Context() context.Context {
+	return ss.ctx
+}
@@ -0,0 +1,196 @@
+package grpcbatch_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/fishy/errbatch"
+	"github.com/fishy/errbatch/grpcbatch"
+)
+
+const method = "/test.Service/Method"
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	for _, c := range []struct {
+		label      string
+		code       codes.Code
+		handlerErr error
+		addErrors  bool
+		debugInfo  bool
+		expected   codes.Code
+		details    int
+	}{
+		{
+			label:    "no-errors",
+			expected: codes.OK,
+		},
+		{
+			label:     "errors-reported-only",
+			addErrors: true,
+			expected:  codes.OK,
+		},
+		{
+			label:     "errors-fail-rpc",
+			code:      codes.Internal,
+			addErrors: true,
+			expected:  codes.Internal,
+		},
+		{
+			label:     "errors-fail-rpc-debug-info",
+			code:      codes.Internal,
+			addErrors: true,
+			debugInfo: true,
+			expected:  codes.Internal,
+			details:   2,
+		},
+		{
+			label:      "handler-error",
+			handlerErr: status.Error(codes.InvalidArgument, "invalid"),
+			addErrors:  true,
+			debugInfo:  true,
+			expected:   codes.InvalidArgument,
+			details:    2,
+		},
+	} {
+		t.Run(c.label, func(t *testing.T) {
+			var reported error
+			interceptor := grpcbatch.Config{
+				Report: func(_ context.Context, m string, err error) {
+					if m != method {
+						t.Errorf("Expected method %q, got %q", method, m)
+					}
+					reported = err
+				},
+				Code:      c.code,
+				DebugInfo: c.debugInfo,
+			}.UnaryServerInterceptor()
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				if c.addErrors {
+					errbatch.AddToContext(ctx, errors.New("foo"))
+					errbatch.AddToContext(ctx, errors.New("bar"))
+				}
+				return req, c.handlerErr
+			}
+
+			_, err := interceptor(
+				context.Background(),
+				"req",
+				&grpc.UnaryServerInfo{FullMethod: method},
+				handler,
+			)
+			st := status.Convert(err)
+			if st.Code() != c.expected {
+				t.Errorf("Expected code %v, got %v", c.expected, st.Code())
+			}
+			if len(st.Details()) != c.details {
+				t.Errorf("Expected %d details, got %v", c.details, st.Details())
+			}
+			for _, detail := range st.Details() {
+				if _, ok := detail.(*errdetails.DebugInfo); !ok {
+					t.Errorf("Expected *errdetails.DebugInfo, got %T", detail)
+				}
+			}
+			if c.addErrors != (reported != nil) {
+				t.Errorf("Unexpected report: %v", reported)
+			}
+		})
+	}
+}
+
+type fakeStream struct {
+	grpc.ServerStream
+}
+
+func (fakeStream) Context() context.Context {
+	return context.Background()
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	interceptor := grpcbatch.Config{
+		Report: func(context.Context, string, error) {},
+		Code:   codes.Unavailable,
+	}.StreamServerInterceptor()
+	err := interceptor(
+		nil,
+		fakeStream{},
+		&grpc.StreamServerInfo{FullMethod: method},
+		func(srv interface{}, ss grpc.ServerStream) error {
+			if !errbatch.AddToContext(ss.Context(), errors.New("foo")) {
+				t.Error("Expected batch in stream context")
+			}
+			return nil
+		},
+	)
+	if code := status.Code(err); code != codes.Unavailable {
+		t.Errorf("Expected code %v, got %v", codes.Unavailable, code)
+	}
+}
+
+func TestEmbedTraceparent(t *testing.T) {
+	const traceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	interceptor := grpcbatch.Config{
+		Report:           func(context.Context, string, error) {},
+		Code:             codes.Internal,
+		DebugInfo:        true,
+		EmbedTraceparent: true,
+	}.UnaryServerInterceptor()
+	ctx := metadata.NewIncomingContext(
+		context.Background(),
+		metadata.Pairs("traceparent", traceparent),
+	)
+	_, err := interceptor(
+		ctx,
+		"req",
+		&grpc.UnaryServerInfo{FullMethod: method},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			errbatch.AddToContext(ctx, errors.New("foo"))
+			return req, nil
+		},
+	)
+
+	details := status.Convert(err).Details()
+	if len(details) != 2 {
+		t.Fatalf("Expected 2 details, got %v", details)
+	}
+	info, ok := details[1].(*errdetails.RequestInfo)
+	if !ok {
+		t.Fatalf("Expected *errdetails.RequestInfo, got %T", details[1])
+	}
+	if info.RequestId != traceparent {
+		t.Errorf("Expected traceparent %q, got %q", traceparent, info.RequestId)
+	}
+}
+
+func TestEmbedTraceparentInvalid(t *testing.T) {
+	interceptor := grpcbatch.Config{
+		Report:           func(context.Context, string, error) {},
+		Code:             codes.Internal,
+		EmbedTraceparent: true,
+	}.UnaryServerInterceptor()
+	ctx := metadata.NewIncomingContext(
+		context.Background(),
+		metadata.Pairs("traceparent", "bogus"),
+	)
+	_, err := interceptor(
+		ctx,
+		"req",
+		&grpc.UnaryServerInfo{FullMethod: method},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			errbatch.AddToContext(ctx, errors.New("foo"))
+			return req, nil
+		},
+	)
+
+	for _, detail := range status.Convert(err).Details() {
+		if info, ok := detail.(*errdetails.RequestInfo); ok {
+			t.Errorf("Expected no RequestInfo, got %q", info.RequestId)
+		}
+	}
+}
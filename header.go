@@ -0,0 +1,114 @@
+package errbatch
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+func defaultHeader(count int) string {
+	return fmt.Sprintf("errbatch: total %d error(s) in this batch", count)
+}
+
+// HeaderData is the data used to execute the template set by
+// WithHeaderTemplate.
+type HeaderData struct {
+	// The number of errors in the batch.
+	Count int
+}
+
+// Plural returns singular when Count is 1, and plural otherwise.
+//
+// It's intended to be called from the header template, for example:
+//
+//	{{.Count}} {{.Plural "error" "errors"}} occurred
+func (hd HeaderData) Plural(singular, plural string) string {
+	if hd.Count == 1 {
+		return singular
+	}
+	return plural
+}
+
+// WithHeaderFunc sets the function used to generate the header of the error
+// message of the batch,
+// which is "errbatch: total %d error(s) in this batch" by default.
+//
+// f will be called with the number of errors in the batch.
+// If it returns an empty string,
+// the error message will only contain the underlying error(s).
+//
+// Nil f restores the default header.
+func WithHeaderFunc(f func(count int) string) Option {
+	if f == nil {
+		f = defaultHeader
+	}
+	return func(o *options) {
+		o.header = f
+	}
+}
+
+// WithHeaderTemplate sets the template used to generate the header of the
+// error message of the batch,
+// which is "errbatch: total %d error(s) in this batch" by default.
+//
+// The template will be executed with HeaderData.
+// If the execution fails, the default header will be used instead.
+//
+// If the template generates an empty string,
+// the error message will only contain the underlying error(s).
+//
+// Nil tmpl restores the default header.
+func WithHeaderTemplate(tmpl *template.Template) Option {
+	if tmpl == nil {
+		return WithHeaderFunc(nil)
+	}
+	return func(o *options) {
+		o.header = func(count int) string {
+			var builder strings.Builder
+			if err := tmpl.Execute(&builder, HeaderData{Count: count}); err != nil {
+				return defaultHeader(count)
+			}
+			return builder.String()
+		}
+	}
+}
+
+// WithHeaderFormat sets the printf formats used to generate the header of the
+// error message of the batch,
+// which is "errbatch: total %d error(s) in this batch" by default.
+//
+// singular is used when the batch contains exactly one error,
+// plural is used otherwise.
+// Both of them will be called with the number of errors as the only arg.
+func WithHeaderFormat(singular, plural string) Option {
+	return func(o *options) {
+		o.header = func(count int) string {
+			if count == 1 {
+				return fmt.Sprintf(singular, count)
+			}
+			return fmt.Sprintf(plural, count)
+		}
+	}
+}
+
+// WithHeaderMinCount omits the header when the batch contains fewer than n
+// errors,
+// so the error message only contains the underlying errors.
+//
+// For example, with WithHeaderMinCount(4),
+// a batch of 2 or 3 errors reads "foo; bar" instead of
+// "errbatch: total 2 error(s) in this batch: foo; bar",
+// which is easier to read in user-facing messages.
+func WithHeaderMinCount(n int) Option {
+	return func(o *options) {
+		o.headerMin = n
+	}
+}
+
+// getHeader returns the header for a batch with count errors.
+func (opts *options) getHeader(count int) string {
+	if count < opts.headerMin {
+		return ""
+	}
+	return opts.header(count)
+}
@@ -0,0 +1,150 @@
+package errbatch_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"text/template"
+
+	"github.com/fishy/errbatch"
+)
+
+func TestHeader(t *testing.T) {
+	for _, c := range []struct {
+		label    string
+		opts     []errbatch.Option
+		expected []string
+	}{
+		{
+			label: "default",
+			expected: []string{
+				"errbatch: total 1 error(s) in this batch: foo",
+				"errbatch: total 2 error(s) in this batch: foo; bar",
+			},
+		},
+		{
+			label: "template",
+			opts: []errbatch.Option{
+				errbatch.WithHeaderTemplate(template.Must(template.New("header").Parse(
+					`{{.Count}} {{.Plural "error" "errors"}} occurred`,
+				))),
+			},
+			expected: []string{
+				"1 error occurred: foo",
+				"2 errors occurred: foo; bar",
+			},
+		},
+		{
+			label: "template-failure",
+			opts: []errbatch.Option{
+				errbatch.WithHeaderTemplate(template.Must(template.New("header").Parse(
+					`{{.NoSuchField}}`,
+				))),
+			},
+			expected: []string{
+				"errbatch: total 1 error(s) in this batch: foo",
+				"errbatch: total 2 error(s) in this batch: foo; bar",
+			},
+		},
+		{
+			label: "nil-template",
+			opts: []errbatch.Option{
+				errbatch.WithHeaderFormat("%d failure", "%d failures"),
+				errbatch.WithHeaderTemplate(nil),
+			},
+			expected: []string{
+				"errbatch: total 1 error(s) in this batch: foo",
+				"errbatch: total 2 error(s) in this batch: foo; bar",
+			},
+		},
+		{
+			label: "nil-func",
+			opts: []errbatch.Option{
+				errbatch.WithHeaderFormat("%d failure", "%d failures"),
+				errbatch.WithHeaderFunc(nil),
+			},
+			expected: []string{
+				"errbatch: total 1 error(s) in this batch: foo",
+				"errbatch: total 2 error(s) in this batch: foo; bar",
+			},
+		},
+		{
+			label: "format",
+			opts: []errbatch.Option{
+				errbatch.WithHeaderFormat("%d failure", "%d failures"),
+			},
+			expected: []string{
+				"1 failure: foo",
+				"2 failures: foo; bar",
+			},
+		},
+		{
+			label: "func",
+			opts: []errbatch.Option{
+				errbatch.WithHeaderFunc(func(count int) string {
+					return fmt.Sprintf("%d Fehler", count)
+				}),
+			},
+			expected: []string{
+				"1 Fehler: foo",
+				"2 Fehler: foo; bar",
+			},
+		},
+		{
+			label: "suppressed",
+			opts: []errbatch.Option{
+				errbatch.WithHeaderFunc(func(int) string {
+					return ""
+				}),
+			},
+			expected: []string{
+				"foo",
+				"foo; bar",
+			},
+		},
+		{
+			label: "empty",
+			opts: []errbatch.Option{
+				errbatch.WithHeaderTemplate(template.Must(template.New("header").Parse(
+					"",
+				))),
+			},
+			expected: []string{
+				"foo",
+				"foo; bar",
+			},
+		},
+		{
+			label: "min-count",
+			opts: []errbatch.Option{
+				errbatch.WithHeaderMinCount(2),
+			},
+			expected: []string{
+				"foo",
+				"errbatch: total 2 error(s) in this batch: foo; bar",
+			},
+		},
+		{
+			label: "min-count-not-reached",
+			opts: []errbatch.Option{
+				errbatch.WithHeaderMinCount(3),
+			},
+			expected: []string{
+				"foo",
+				"foo; bar",
+			},
+		},
+	} {
+		t.Run(c.label, func(t *testing.T) {
+			batch := errbatch.New(c.opts...)
+			batch.Add(errors.New("foo"))
+			if actual := batch.Error(); actual != c.expected[0] {
+				t.Errorf("Expected %q, got %q", c.expected[0], actual)
+			}
+			batch.Add(errors.New("bar"))
+			if actual := batch.Error(); actual != c.expected[1] {
+				t.Errorf("Expected %q, got %q", c.expected[1], actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+// Package httpbatch provides a net/http middleware that collects per-request
+// errors into errbatch.ConcurrentErrBatch.
+package httpbatch
+
+import (
+	"bufio"
+	"log"
+	"net"
+	"net/http"
+
+	"github.com/fishy/errbatch"
+)
+
+// MiddlewareConfig configures the net/http middleware created by Wrap.
+type MiddlewareConfig struct {
+	// Report is called with the compiled batch when the request finishes,
+	// if any error was added during the request.
+	//
+	// If it's nil, the error will be logged via the log package.
+	Report func(r *http.Request, err error)
+
+	// If Status is non-zero and any error was added during the request,
+	// it will be written as the response status when the request finishes,
+	// unless the handler already wrote the response header.
+	Status int
+}
+
+// Middleware wraps next with the default MiddlewareConfig.
+//
+// See MiddlewareConfig.Wrap for details.
+func Middleware(next http.Handler) http.Handler {
+	return MiddlewareConfig{}.Wrap(next)
+}
+
+// Wrap returns a net/http middleware that installs an
+// errbatch.ConcurrentErrBatch into
+// the context of every request.
+//
+// Handlers (and any helpers they call, even in other goroutines) can add
+// errors to it via errbatch.AddToContext or errbatch.FromContext.
+// When next returns,
+// the batch is compiled and reported according to the config.
+func (cfg MiddlewareConfig) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		batch := new(errbatch.ConcurrentErrBatch)
+		r = r.WithContext(errbatch.NewContext(r.Context(), batch))
+
+		var rw *headerRecorder
+		if cfg.Status != 0 {
+			rw = &headerRecorder{ResponseWriter: w}
+			w = rw
+		}
+
+		next.ServeHTTP(w, r)
+
+		err := batch.Compile()
+		if err == nil {
+			return
+		}
+		if rw != nil && !rw.wroteHeader {
+			rw.WriteHeader(cfg.Status)
+		}
+		if cfg.Report != nil {
+			cfg.Report(r, err)
+		} else {
+			log.Printf("errbatch: %s %s: %v", r.Method, r.URL.Path, err)
+		}
+	})
+}
+
+// headerRecorder records whether the response header was written.
+type headerRecorder struct {
+	http.ResponseWriter
+
+	wroteHeader bool
+}
+
+func (hr *headerRecorder) WriteHeader(code int) {
+	hr.wroteHeader = true
+	hr.ResponseWriter.WriteHeader(code)
+}
+
+func (hr *headerRecorder) Write(b []byte) (int, error) {
+	hr.wroteHeader = true
+	return hr.ResponseWriter.Write(b)
+}
+
+// Unwrap allows http.ResponseController to access the original
+// http.ResponseWriter.
+func (hr *headerRecorder) Unwrap() http.ResponseWriter {
+	return hr.ResponseWriter
+}
+
+// Flush implements http.Flusher, if the original http.ResponseWriter
+// supports it.
+func (hr *headerRecorder) Flush() {
+	hr.wroteHeader = true
+	http.NewResponseController(hr.ResponseWriter).Flush()
+}
+
+// Hijack implements http.Hijacker, if the original http.ResponseWriter
+// supports it.
+func (hr *headerRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := http.NewResponseController(hr.ResponseWriter).Hijack()
+	if err == nil {
+		hr.wroteHeader = true
+	}
+	return conn, rw, err
+}
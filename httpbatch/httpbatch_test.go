@@ -0,0 +1,95 @@
+package httpbatch_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fishy/errbatch"
+	"github.com/fishy/errbatch/httpbatch"
+)
+
+func TestMiddleware(t *testing.T) {
+	var reported error
+	handler := httpbatch.MiddlewareConfig{
+		Report: func(r *http.Request, err error) {
+			reported = err
+		},
+		Status: http.StatusInternalServerError,
+	}.Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/ok" {
+			return
+		}
+		errbatch.AddToContext(r.Context(), errors.New("foo"))
+		errbatch.FromContext(r.Context()).Add(errors.New("bar"))
+		if r.URL.Path == "/written" {
+			w.WriteHeader(http.StatusAccepted)
+		}
+	}))
+
+	for _, c := range []struct {
+		path     string
+		status   int
+		reported string
+	}{
+		{
+			path:   "/ok",
+			status: http.StatusOK,
+		},
+		{
+			path:     "/fail",
+			status:   http.StatusInternalServerError,
+			reported: "errbatch: total 2 error(s) in this batch: foo; bar",
+		},
+		{
+			path:     "/written",
+			status:   http.StatusAccepted,
+			reported: "errbatch: total 2 error(s) in this batch: foo; bar",
+		},
+	} {
+		t.Run(c.path, func(t *testing.T) {
+			reported = nil
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, c.path, nil))
+			if w.Code != c.status {
+				t.Errorf("Expected status %d, got %d", c.status, w.Code)
+			}
+			if c.reported == "" {
+				if reported != nil {
+					t.Errorf("Expected nothing reported, got %v", reported)
+				}
+			} else if reported == nil || reported.Error() != c.reported {
+				t.Errorf("Expected %q reported, got %v", c.reported, reported)
+			}
+		})
+	}
+}
+
+func TestMiddlewareFlusherHijacker(t *testing.T) {
+	var flusher, hijacker bool
+	handler := httpbatch.MiddlewareConfig{
+		Report: func(*http.Request, error) {},
+		Status: http.StatusInternalServerError,
+	}.Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, flusher = w.(http.Flusher)
+		_, hijacker = w.(http.Hijacker)
+		errbatch.AddToContext(r.Context(), errors.New("foo"))
+		w.(http.Flusher).Flush()
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if !flusher {
+		t.Error("Expected the ResponseWriter to implement http.Flusher")
+	}
+	if !hijacker {
+		t.Error("Expected the ResponseWriter to implement http.Hijacker")
+	}
+	if !w.Flushed {
+		t.Error("Expected the response to be flushed")
+	}
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status %d after flush, got %d", http.StatusOK, w.Code)
+	}
+}
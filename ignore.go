@@ -0,0 +1,93 @@
+package errbatch
+
+import (
+	"context"
+	"errors"
+)
+
+// WithIgnore makes Add silently skip errors matching any of errs via
+// errors.Is,
+// instead of guarding every Add with checks like
+// `if errors.Is(err, sql.ErrNoRows) { continue }`.
+//
+// When a batch is added, its underlying errors matching errs are skipped.
+// Other aggregated errors (e.g. errors.Join) are skipped as a whole when any
+// of their errors match, unless they are flattened by WithRecursiveFlatten.
+// Using WithIgnore multiple times accumulates the errors to ignore.
+// The number of skipped errors can be retrieved via Skipped.
+func WithIgnore(errs ...error) Option {
+	return func(o *options) {
+		o.ignore = append(o.ignore[:len(o.ignore):len(o.ignore)], errs...)
+	}
+}
+
+// ignored reports whether err should be skipped according to WithIgnore.
+func (opts *options) ignored(err error) bool {
+	for _, target := range opts.ignore {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// skips reports whether adding err into the batch skips it entirely
+// according to WithIgnore,
+// i.e. it's nil or ignored, or all the underlying errors of the batch are.
+func (opts *options) skips(err error) bool {
+	if err == nil {
+		return true
+	}
+	if len(opts.ignore) == 0 {
+		return false
+	}
+	if entries, ok := opts.flatten(err, callSite{}.newEntry); ok {
+		for _, e := range entries {
+			if !opts.ignored(e.err) {
+				return false
+			}
+		}
+		return true
+	}
+	return opts.ignored(err)
+}
+
+// filter returns entries without the ones to be skipped according to
+// WithIgnore, and the number of skipped ones.
+func (opts *options) filter(entries []entry) (kept []entry, skipped int) {
+	if len(opts.ignore) == 0 {
+		return entries, 0
+	}
+	kept = make([]entry, 0, len(entries))
+	for _, e := range entries {
+		if !opts.ignored(e.err) {
+			kept = append(kept, e)
+		}
+	}
+	skipped = len(entries) - len(kept)
+	countDropped(skipped)
+	return kept, skipped
+}
+
+// WithSkipCanceled makes Add silently skip context.Canceled errors (including
+// the ones wrapping it),
+// so batches from canceled fan-outs are not dominated by the cancellation
+// noise, while the real failures are still retained.
+//
+// It's a shorthand for WithIgnore(context.Canceled).
+// The number of skipped errors can be retrieved via Skipped.
+func WithSkipCanceled() Option {
+	return WithIgnore(context.Canceled)
+}
+
+// Skipped returns the number of errors skipped by WithIgnore (or
+// WithSkipCanceled) since the batch is created or last cleared.
+func (eb *ErrBatch) Skipped() int {
+	return eb.skipped
+}
+
+// Skipped returns the number of errors skipped by WithIgnore (or
+// WithSkipCanceled) since the batch is created or last cleared.
+func (cb *ConcurrentErrBatch) Skipped() int {
+	return int(cb.getList().skipped.Load())
+}
@@ -0,0 +1,89 @@
+package errbatch_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"testing"
+
+	"github.com/fishy/errbatch"
+)
+
+func TestWithIgnore(t *testing.T) {
+	opts := []errbatch.Option{
+		errbatch.WithIgnore(sql.ErrNoRows),
+		errbatch.WithIgnore(io.EOF),
+	}
+	add := func(adder interface{ Add(error) }) {
+		adder.Add(sql.ErrNoRows)
+		adder.Add(fmt.Errorf("query: %w", sql.ErrNoRows))
+		adder.Add(errors.New("foo"))
+		adder.Add(io.EOF)
+		adder.Add(errors.Join(errors.New("bar"), io.EOF))
+
+		var other errbatch.ErrBatch
+		other.Add(io.EOF)
+		other.Add(errors.New("foobar"))
+		adder.Add(&other)
+	}
+	expected := []string{"foo", "foobar"}
+
+	t.Run("batch", func(t *testing.T) {
+		batch := errbatch.New(opts...)
+		add(batch)
+		if actual := messages(batch.GetErrors()); !slices.Equal(actual, expected) {
+			t.Errorf("Expected %q, got %q", expected, actual)
+		}
+	})
+
+	t.Run("concurrent", func(t *testing.T) {
+		cb := errbatch.NewConcurrent(opts...)
+		add(cb)
+		if actual := messages(cb.GetErrors()); !slices.Equal(actual, expected) {
+			t.Errorf("Expected %q, got %q", expected, actual)
+		}
+	})
+}
+
+func TestWithSkipCanceled(t *testing.T) {
+	batch := errbatch.New(errbatch.WithSkipCanceled())
+	batch.Add(context.Canceled)
+	batch.Add(fmt.Errorf("call: %w", context.Canceled))
+	batch.Add(context.DeadlineExceeded)
+	expected := []string{"context deadline exceeded"}
+	if actual := messages(batch.GetErrors()); !slices.Equal(actual, expected) {
+		t.Errorf("Expected %q, got %q", expected, actual)
+	}
+	if actual := batch.Skipped(); actual != 2 {
+		t.Errorf("Expected 2 skipped, got %d", actual)
+	}
+
+	t.Run("batch", func(t *testing.T) {
+		var other errbatch.ErrBatch
+		other.Add(context.Canceled)
+		other.Add(errors.New("foo"))
+		batch.Add(&other)
+		if actual := batch.Skipped(); actual != 3 {
+			t.Errorf("Expected 3 skipped, got %d", actual)
+		}
+		batch.Clear()
+		if actual := batch.Skipped(); actual != 0 {
+			t.Errorf("Expected 0 skipped after Clear, got %d", actual)
+		}
+	})
+
+	t.Run("concurrent", func(t *testing.T) {
+		cb := errbatch.NewConcurrent(errbatch.WithSkipCanceled())
+		cb.Add(context.Canceled)
+		cb.Add(errors.New("foo"))
+		if actual := cb.Skipped(); actual != 1 {
+			t.Errorf("Expected 1 skipped, got %d", actual)
+		}
+		if actual := cb.Snapshot().Skipped(); actual != 1 {
+			t.Errorf("Expected 1 skipped in snapshot, got %d", actual)
+		}
+	})
+}
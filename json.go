@@ -0,0 +1,77 @@
+package errbatch
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+)
+
+// Make sure *ErrBatch satisfies json.Marshaler interface.
+var _ json.Marshaler = (*ErrBatch)(nil)
+
+// traceparentRegexp matches the W3C Trace Context traceparent header,
+// see https://www.w3.org/TR/trace-context/#traceparent-header.
+var traceparentRegexp = regexp.MustCompile(`^[0-9a-f]{2}-[0-9a-f]{32}-[0-9a-f]{16}-[0-9a-f]{2}$`)
+
+// ValidTraceparent reports whether traceparent is a valid W3C Trace Context
+// traceparent header value, as accepted by SetTraceparent.
+func ValidTraceparent(traceparent string) bool {
+	return traceparentRegexp.MatchString(traceparent)
+}
+
+// SetTraceparent sets the W3C Trace Context traceparent of the batch
+// (e.g. from the traceparent header of the current request),
+// which is embedded when the batch is serialized (e.g. MarshalJSON),
+// so downstream error pipelines can correlate the batch with the trace.
+//
+// It returns an error if traceparent is not a valid traceparent header value,
+// in which case the batch is not changed.
+// Empty traceparent clears the one previously set.
+func (eb *ErrBatch) SetTraceparent(traceparent string) error {
+	if traceparent != "" && !ValidTraceparent(traceparent) {
+		return fmt.Errorf("errbatch: invalid traceparent %q", traceparent)
+	}
+	eb.traceparent = traceparent
+	return nil
+}
+
+// Traceparent returns the traceparent set by SetTraceparent.
+func (eb *ErrBatch) Traceparent() string {
+	return eb.traceparent
+}
+
+type jsonBatch struct {
+	Count       int         `json:"count"`
+	Errors      []jsonEntry `json:"errors"`
+	Traceparent string      `json:"traceparent,omitempty"`
+}
+
+type jsonEntry struct {
+	Message string `json:"message"`
+	Type    string `json:"type"`
+	TraceID string `json:"trace_id,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler.
+//
+// The batch is encoded as an object with the number of errors ("count"),
+// the message, type name, and trace ID (if any) of each of the underlying
+// errors ("errors"),
+// and the traceparent set by SetTraceparent (if any).
+// The messages are redacted by WithRedactor.
+func (eb *ErrBatch) MarshalJSON() ([]byte, error) {
+	opts := eb.getOptions()
+	jb := jsonBatch{
+		Count:       len(eb.entries),
+		Errors:      make([]jsonEntry, len(eb.entries)),
+		Traceparent: eb.traceparent,
+	}
+	for i, e := range eb.entries {
+		jb.Errors[i] = jsonEntry{
+			Message: opts.redact(errorMessage(e.err)),
+			Type:    typeName(e.err),
+			TraceID: e.getMeta().traceID,
+		}
+	}
+	return json.Marshal(jb)
+}
@@ -0,0 +1,67 @@
+package errbatch_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/fishy/errbatch"
+)
+
+const traceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+func TestMarshalJSON(t *testing.T) {
+	var batch errbatch.ErrBatch
+	batch.Add(errors.New("foo"))
+	batch.AddCoded("code", customError{})
+
+	buf, err := json.Marshal(&batch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const expected = `{"count":2,"errors":[` +
+		`{"message":"foo","type":"*errors.errorString"},` +
+		`{"message":"custom","type":"errbatch_test.customError"}` +
+		`]}`
+	if string(buf) != expected {
+		t.Errorf("Expected %s, got %s", expected, buf)
+	}
+
+	if err := batch.SetTraceparent(traceparent); err != nil {
+		t.Fatal(err)
+	}
+	buf, err = json.Marshal(&batch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded struct {
+		Traceparent string `json:"traceparent"`
+	}
+	if err := json.Unmarshal(buf, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Traceparent != traceparent {
+		t.Errorf("Expected traceparent %q, got %q", traceparent, decoded.Traceparent)
+	}
+
+	var copied *errbatch.ErrBatch
+	if !errors.As(&batch, &copied) || copied.Traceparent() != traceparent {
+		t.Errorf("Expected traceparent to be kept by errors.As, got %#v", copied)
+	}
+}
+
+func TestSetTraceparent(t *testing.T) {
+	var batch errbatch.ErrBatch
+	for _, tp := range []string{
+		"foo",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00F067AA0BA902B7-01",
+	} {
+		if err := batch.SetTraceparent(tp); err == nil {
+			t.Errorf("Expected error for invalid traceparent %q", tp)
+		}
+	}
+	if batch.Traceparent() != "" {
+		t.Errorf("Expected invalid traceparents to be ignored, got %q", batch.Traceparent())
+	}
+}
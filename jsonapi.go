@@ -0,0 +1,64 @@
+package errbatch
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+)
+
+// JSONAPIError is an error object in the "errors" array of a JSON:API
+// document.
+//
+// See https://jsonapi.org/format/#error-objects.
+type JSONAPIError struct {
+	Status string `json:"status,omitempty"`
+	Code   string `json:"code,omitempty"`
+	Title  string `json:"title,omitempty"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// Error implements error.
+func (je JSONAPIError) Error() string {
+	return je.Detail
+}
+
+// ToJSONAPIErrors converts the batch into JSON:API error objects,
+// one for each of its underlying error(s).
+//
+// For each error:
+//
+//   - Status is the HTTP status code of the error,
+//     if it implements StatusCode() int (directly or via errors.As).
+//   - Code is the first code of the error, if any (see AddCoded).
+//   - Title is the name the error was added with via AddNamed,
+//     or the HTTP status text if there's no name but a status.
+//   - Detail is the error message,
+//     without the name prefix for errors added via AddNamed.
+func (eb *ErrBatch) ToJSONAPIErrors() []JSONAPIError {
+	if len(eb.entries) == 0 {
+		return nil
+	}
+	jes := make([]JSONAPIError, 0, len(eb.entries))
+	for _, e := range eb.entries {
+		je := JSONAPIError{
+			Detail: errorMessage(e.err),
+		}
+		var sc interface {
+			StatusCode() int
+		}
+		if errors.As(e.err, &sc) {
+			je.Status = strconv.Itoa(sc.StatusCode())
+			je.Title = http.StatusText(sc.StatusCode())
+		}
+		if codes := Codes(e.err); len(codes) > 0 {
+			je.Code = codes[0]
+		}
+		var ne *namedError
+		if errors.As(e.err, &ne) {
+			je.Title = ne.name
+			je.Detail = errorMessage(ne.err)
+		}
+		jes = append(jes, je)
+	}
+	return jes
+}
@@ -0,0 +1,45 @@
+package errbatch_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/fishy/errbatch"
+)
+
+type statusError int
+
+func (se statusError) Error() string {
+	return fmt.Sprintf("status %d", int(se))
+}
+
+func (se statusError) StatusCode() int {
+	return int(se)
+}
+
+func TestToJSONAPIErrors(t *testing.T) {
+	var batch errbatch.ErrBatch
+	if jes := batch.ToJSONAPIErrors(); jes != nil {
+		t.Errorf("Expected nil for empty batch, got %#v", jes)
+	}
+
+	batch.Add(errors.New("foo"))
+	batch.AddCoded("invalid", statusError(http.StatusBadRequest))
+	batch.AddNamed("name", errors.New("bar"))
+
+	buf, err := json.Marshal(map[string]any{"errors": batch.ToJSONAPIErrors()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	const expected = `{"errors":[` +
+		`{"detail":"foo"},` +
+		`{"status":"400","code":"invalid","title":"Bad Request","detail":"status 400"},` +
+		`{"title":"name","detail":"bar"}` +
+		`]}`
+	if string(buf) != expected {
+		t.Errorf("Expected %s, got %s", expected, buf)
+	}
+}
@@ -0,0 +1,78 @@
+package errbatch
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+)
+
+// Tag keys of the tags of each of the underlying errors,
+// as used by WriteJSONL and Records.
+const (
+	// TagCode is the tag key of the code(s) added via AddCoded,
+	// multiple codes are joined by ",".
+	TagCode = "code"
+
+	// TagName is the tag key of the name added via AddNamed.
+	TagName = "name"
+
+	// TagTraceID is the tag key of the trace ID tagged by WithTraceExtractor.
+	TagTraceID = "trace_id"
+)
+
+// tags returns the tags of the entry, or nil if it has none.
+func (e entry) tags() map[string]string {
+	var tags map[string]string
+	set := func(key, value string) {
+		if value == "" {
+			return
+		}
+		if tags == nil {
+			tags = make(map[string]string)
+		}
+		tags[key] = value
+	}
+	set(TagCode, strings.Join(Codes(e.err), ","))
+	var ne *namedError
+	if errors.As(e.err, &ne) {
+		set(TagName, ne.name)
+	}
+	set(TagTraceID, e.getMeta().traceID)
+	return tags
+}
+
+type jsonlEntry struct {
+	Index   int               `json:"index"`
+	Message string            `json:"message"`
+	Type    string            `json:"type"`
+	Tags    map[string]string `json:"tags,omitempty"`
+}
+
+// WriteJSONL writes the underlying errors of the batch into w in JSON Lines
+// format, one JSON object per error,
+// so very large batches can be streamed into log systems and aggregated per
+// error.
+//
+// Each object contains the index of the error in the batch ("index"),
+// its message ("message"), type name ("type"),
+// and tags ("tags", see TagCode, TagName and TagTraceID) if any.
+// The messages are redacted by WithRedactor.
+//
+// It returns the first error returned by w, if any.
+// Nothing is written for an empty batch.
+func (eb *ErrBatch) WriteJSONL(w io.Writer) error {
+	opts := eb.getOptions()
+	enc := json.NewEncoder(w)
+	for i, e := range eb.entries {
+		if err := enc.Encode(jsonlEntry{
+			Index:   i,
+			Message: opts.redact(errorMessage(e.err)),
+			Type:    typeName(e.err),
+			Tags:    e.tags(),
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
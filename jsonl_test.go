@@ -0,0 +1,57 @@
+package errbatch_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/fishy/errbatch"
+)
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("write failed")
+}
+
+func TestWriteJSONL(t *testing.T) {
+	batch := errbatch.New(errbatch.WithTraceExtractor(func(ctx context.Context) string {
+		id, _ := ctx.Value(traceKey).(string)
+		return id
+	}))
+	batch.Add(errors.New("foo"))
+	batch.AddCoded("code", customError{})
+	batch.AddNamed("field", errors.New("bar"))
+	batch.AddContext(context.WithValue(context.Background(), traceKey, "abc123"), errors.New("foobar"))
+
+	var sb strings.Builder
+	if err := batch.WriteJSONL(&sb); err != nil {
+		t.Fatal(err)
+	}
+	const expected = `{"index":0,"message":"foo","type":"*errors.errorString"}
+{"index":1,"message":"custom","type":"errbatch_test.customError","tags":{"code":"code"}}
+{"index":2,"message":"field: bar","type":"*errors.errorString","tags":{"name":"field"}}
+{"index":3,"message":"foobar","type":"*errors.errorString","tags":{"trace_id":"abc123"}}
+`
+	if actual := sb.String(); actual != expected {
+		t.Errorf("Expected %s, got %s", expected, actual)
+	}
+
+	t.Run("empty", func(t *testing.T) {
+		var batch errbatch.ErrBatch
+		var sb strings.Builder
+		if err := batch.WriteJSONL(&sb); err != nil {
+			t.Fatal(err)
+		}
+		if sb.Len() != 0 {
+			t.Errorf("Expected nothing written, got %q", sb.String())
+		}
+	})
+
+	t.Run("write-error", func(t *testing.T) {
+		if err := batch.WriteJSONL(failingWriter{}); err == nil {
+			t.Error("Expected error, got nil")
+		}
+	})
+}
@@ -0,0 +1,29 @@
+package errbatch
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Logfmt renders the batch in logfmt, with the number of errors ("count") and
+// the message of each of the underlying errors ("err_0", "err_1", etc.),
+// so log pipelines standardized on logfmt can extract them as fields.
+//
+// The messages are always quoted, and redacted by WithRedactor.
+//
+// For example:
+//
+//	count=2 err_0="foo" err_1="bar baz"
+func (eb *ErrBatch) Logfmt() string {
+	opts := eb.getOptions()
+	var sb strings.Builder
+	sb.WriteString("count=")
+	sb.WriteString(strconv.Itoa(len(eb.entries)))
+	for i, e := range eb.entries {
+		sb.WriteString(" err_")
+		sb.WriteString(strconv.Itoa(i))
+		sb.WriteString("=")
+		sb.WriteString(strconv.Quote(opts.redact(errorMessage(e.err))))
+	}
+	return sb.String()
+}
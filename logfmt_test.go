@@ -0,0 +1,53 @@
+package errbatch_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/fishy/errbatch"
+)
+
+func TestLogfmt(t *testing.T) {
+	for _, c := range []struct {
+		label    string
+		errs     []error
+		opts     []errbatch.Option
+		expected string
+	}{
+		{
+			label:    "empty",
+			expected: "count=0",
+		},
+		{
+			label: "quoted",
+			errs: []error{
+				errors.New("foo"),
+				errors.New(`bar "baz"` + "\n"),
+			},
+			expected: `count=2 err_0="foo" err_1="bar \"baz\"\n"`,
+		},
+		{
+			label: "redacted",
+			errs: []error{
+				errors.New("secret"),
+			},
+			opts: []errbatch.Option{
+				errbatch.WithRedactor(func(string) string {
+					return "redacted"
+				}),
+			},
+			expected: `count=1 err_0="redacted"`,
+		},
+	} {
+		t.Run(c.label, func(t *testing.T) {
+			batch := errbatch.New(c.opts...)
+			for _, err := range c.errs {
+				batch.Add(err)
+			}
+			actual := batch.Logfmt()
+			if actual != c.expected {
+				t.Errorf("Expected %q, got %q", c.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+// Package logrusbatch provides helpers to log errbatch.ErrBatch with logrus
+// in a structured way.
+package logrusbatch
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/fishy/errbatch"
+)
+
+// Field keys used by Fields.
+const (
+	CountKey    = errbatch.FieldCount
+	MessagesKey = errbatch.FieldMessages
+	TypesKey    = errbatch.FieldTypes
+)
+
+// Fields returns logrus.Fields describing err.
+//
+// If err is an ErrBatch (or wraps one),
+// the underlying errors will be described individually,
+// the same as (*errbatch.ErrBatch).Fields.
+// Otherwise err is described as a single error with its message and %T type,
+// unaffected by the options set via errbatch.SetDefaults
+// (e.g. WithIgnore or WithRedactor).
+//
+// Nil err results in a count of 0 and empty messages and types.
+//
+// Example:
+//
+//	logrus.WithFields(logrusbatch.Fields(err)).Error("workers failed")
+func Fields(err error) logrus.Fields {
+	if err == nil {
+		return logrus.Fields{
+			CountKey:    0,
+			MessagesKey: []string{},
+			TypesKey:    []string{},
+		}
+	}
+	var batch *errbatch.ErrBatch
+	if errors.As(err, &batch) {
+		return logrus.Fields(batch.Fields())
+	}
+	return logrus.Fields{
+		CountKey:    1,
+		MessagesKey: []string{err.Error()},
+		TypesKey:    []string{fmt.Sprintf("%T", err)},
+	}
+}
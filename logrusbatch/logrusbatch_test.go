@@ -0,0 +1,80 @@
+package logrusbatch_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/fishy/errbatch"
+	"github.com/fishy/errbatch/logrusbatch"
+)
+
+func TestFields(t *testing.T) {
+	var batch errbatch.ErrBatch
+	batch.Add(errors.New("foo"))
+	batch.Add(errors.New("bar"))
+
+	for _, c := range []struct {
+		label    string
+		err      error
+		expected logrus.Fields
+	}{
+		{
+			label: "nil",
+			err:   nil,
+			expected: logrus.Fields{
+				logrusbatch.CountKey:    0,
+				logrusbatch.MessagesKey: []string{},
+				logrusbatch.TypesKey:    []string{},
+			},
+		},
+		{
+			label: "single",
+			err:   errors.New("foo"),
+			expected: logrus.Fields{
+				logrusbatch.CountKey:    1,
+				logrusbatch.MessagesKey: []string{"foo"},
+				logrusbatch.TypesKey:    []string{"*errors.errorString"},
+			},
+		},
+		{
+			label: "batch",
+			err:   batch.Compile(),
+			expected: logrus.Fields{
+				logrusbatch.CountKey:    2,
+				logrusbatch.MessagesKey: []string{"foo", "bar"},
+				logrusbatch.TypesKey: []string{
+					"*errors.errorString",
+					"*errors.errorString",
+				},
+			},
+		},
+	} {
+		t.Run(c.label, func(t *testing.T) {
+			actual := logrusbatch.Fields(c.err)
+			if !reflect.DeepEqual(actual, c.expected) {
+				t.Errorf("Expected %#v, got %#v", c.expected, actual)
+			}
+		})
+	}
+}
+
+func TestFieldsIgnoresDefaults(t *testing.T) {
+	ignored := errors.New("foo")
+	errbatch.SetDefaults(errbatch.WithIgnore(ignored))
+	t.Cleanup(func() {
+		errbatch.SetDefaults()
+	})
+
+	expected := logrus.Fields{
+		logrusbatch.CountKey:    1,
+		logrusbatch.MessagesKey: []string{"foo"},
+		logrusbatch.TypesKey:    []string{"*errors.errorString"},
+	}
+	actual := logrusbatch.Fields(ignored)
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %#v, got %#v", expected, actual)
+	}
+}
@@ -0,0 +1,99 @@
+package errbatch
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// MarshalMsgpack implements the Marshaler interface of
+// github.com/vmihailenco/msgpack/v5, without depending on it.
+//
+// The batch is encoded in the same layout as MarshalCBOR,
+// as msgpack arrays and strings.
+// Like MarshalBinary, the messages are redacted by WithRedactor,
+// unless the batch is created with WithLosslessEncoding,
+// so it can be restored losslessly by UnmarshalMsgpack.
+func (eb *ErrBatch) MarshalMsgpack() ([]byte, error) {
+	return eb.appendWire(nil, msgpackFormat{}), nil
+}
+
+// UnmarshalMsgpack implements the Unmarshaler interface of
+// github.com/vmihailenco/msgpack/v5, without depending on it.
+//
+// It replaces the errors and traceparent of the batch with the ones decoded
+// from data produced by MarshalMsgpack,
+// the decoded errors are of type *DecodedError.
+// The options of the batch are kept and applied to the decoded errors
+// (e.g. WithIgnore, WithDedup and the retention policies), the same as Add.
+// On errors the batch is not changed.
+func (eb *ErrBatch) UnmarshalMsgpack(data []byte) error {
+	if err := eb.decodeWire(data, msgpackFormat{}); err != nil {
+		return fmt.Errorf("errbatch: malformed msgpack encoding: %w", err)
+	}
+	return nil
+}
+
+// msgpackFormat is the wireFormat of msgpack,
+// see https://github.com/msgpack/msgpack/blob/master/spec.md.
+type msgpackFormat struct{}
+
+func (msgpackFormat) appendArray(buf []byte, n int) []byte {
+	switch {
+	case n < 16:
+		return append(buf, 0x90|byte(n))
+	case n <= 0xffff:
+		return binary.BigEndian.AppendUint16(append(buf, 0xdc), uint16(n))
+	default:
+		return binary.BigEndian.AppendUint32(append(buf, 0xdd), uint32(n))
+	}
+}
+
+func (msgpackFormat) appendString(buf []byte, s string) []byte {
+	switch n := len(s); {
+	case n < 32:
+		buf = append(buf, 0xa0|byte(n))
+	case n <= 0xff:
+		buf = append(buf, 0xd9, byte(n))
+	case n <= 0xffff:
+		buf = binary.BigEndian.AppendUint16(append(buf, 0xda), uint16(n))
+	default:
+		buf = binary.BigEndian.AppendUint32(append(buf, 0xdb), uint32(n))
+	}
+	return append(buf, s...)
+}
+
+func (msgpackFormat) readArray(d *wireDecoder) int {
+	switch b := d.byte(); {
+	case d.err != nil:
+		return 0
+	case b&0xf0 == 0x90:
+		return d.count(uint64(b & 0x0f))
+	case b == 0xdc:
+		return d.count(d.uint(2))
+	case b == 0xdd:
+		return d.count(d.uint(4))
+	default:
+		d.fail(fmt.Errorf("expected msgpack array, got 0x%02x", b))
+		return 0
+	}
+}
+
+func (msgpackFormat) readString(d *wireDecoder) string {
+	var n int
+	switch b := d.byte(); {
+	case d.err != nil:
+		return ""
+	case b&0xe0 == 0xa0:
+		n = d.count(uint64(b & 0x1f))
+	case b == 0xd9:
+		n = d.count(d.uint(1))
+	case b == 0xda:
+		n = d.count(d.uint(2))
+	case b == 0xdb:
+		n = d.count(d.uint(4))
+	default:
+		d.fail(fmt.Errorf("expected msgpack string, got 0x%02x", b))
+		return ""
+	}
+	return string(d.next(n))
+}
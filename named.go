@@ -0,0 +1,81 @@
+package errbatch
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+type namedError struct {
+	name string
+	err  error
+}
+
+func (ne *namedError) Error() string {
+	return ne.name + ": " + errorMessage(ne.err)
+}
+
+// Format keeps verbose formatting (e.g. stack traces) of the underlying error.
+func (ne *namedError) Format(s fmt.State, verb rune) {
+	if verb == 'v' && s.Flag('+') {
+		fmt.Fprintf(s, "%s: %+v", ne.name, ne.err)
+		return
+	}
+	fmt.Fprintf(s, fmt.FormatString(s, verb), ne.Error())
+}
+
+func (ne *namedError) Unwrap() error {
+	return ne.err
+}
+
+// AddNamed adds an error keyed by name into the batch.
+//
+// The error message will be prefixed with the name,
+// e.g. "name: original error message".
+//
+// If the error is also an ErrBatch,
+// the name will be attached to each of its underlying error(s).
+//
+// Nil error will be skipped.
+func (eb *ErrBatch) AddNamed(name string, err error) {
+	eb.add(err, func(e *entry) {
+		e.err = &namedError{name: name, err: e.err}
+	})
+}
+
+// FromMap creates a new batch containing the errors in m,
+// each keyed by its key in m as if added via AddNamed.
+//
+// The errors are added in the order of sorted keys,
+// so the result is deterministic.
+// Nil errors are skipped.
+func FromMap(m map[string]error) *ErrBatch {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	batch := new(ErrBatch)
+	for _, k := range keys {
+		batch.AddNamed(k, m[k])
+	}
+	return batch
+}
+
+// ErrorsByKey returns the errors added via AddNamed or FromMap,
+// grouped by their names.
+//
+// The errors in the returned map are the original errors,
+// without the name prefixes in their messages.
+// Errors added without names are not included.
+func (eb *ErrBatch) ErrorsByKey() map[string][]error {
+	m := make(map[string][]error)
+	for _, e := range eb.entries {
+		var ne *namedError
+		if errors.As(e.err, &ne) {
+			m[ne.name] = append(m[ne.name], ne.err)
+		}
+	}
+	return m
+}
@@ -0,0 +1,61 @@
+package errbatch_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/fishy/errbatch"
+)
+
+func TestAddNamed(t *testing.T) {
+	err0 := errors.New("foo")
+	var batch errbatch.ErrBatch
+	batch.AddNamed("nil", nil)
+	batch.AddNamed("first", err0)
+	var nested errbatch.ErrBatch
+	nested.Add(errors.New("bar"))
+	nested.Add(errors.New("foobar"))
+	batch.AddNamed("second", &nested)
+
+	expected := "errbatch: total 3 error(s) in this batch: first: foo; second: bar; second: foobar"
+	if actual := batch.Error(); actual != expected {
+		t.Errorf("Expected %q, got %q", expected, actual)
+	}
+	if !errors.Is(batch.GetErrors()[0], err0) {
+		t.Errorf("Expected %v to wrap %v", batch.GetErrors()[0], err0)
+	}
+}
+
+func TestFromMap(t *testing.T) {
+	batch := errbatch.FromMap(map[string]error{
+		"c": errors.New("foobar"),
+		"a": errors.New("foo"),
+		"d": nil,
+		"b": errors.New("bar"),
+	})
+	expected := "errbatch: total 3 error(s) in this batch: a: foo; b: bar; c: foobar"
+	if actual := batch.Error(); actual != expected {
+		t.Errorf("Expected %q, got %q", expected, actual)
+	}
+}
+
+func TestErrorsByKey(t *testing.T) {
+	err0 := errors.New("foo")
+	err1 := errors.New("bar")
+	err2 := errors.New("foobar")
+	batch := errbatch.FromMap(map[string]error{
+		"a": err0,
+		"b": err1,
+	})
+	batch.AddNamed("a", err2)
+	batch.Add(errors.New("unnamed"))
+
+	expected := map[string][]error{
+		"a": {err0, err2},
+		"b": {err1},
+	}
+	if actual := batch.ErrorsByKey(); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %#v, got %#v", expected, actual)
+	}
+}
@@ -0,0 +1,94 @@
+package errbatch
+
+import (
+	"errors"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var (
+	addressRegexp = regexp.MustCompile(`0x[0-9a-fA-F]+`)
+	portRegexp    = regexp.MustCompile(`(\d{1,3}(?:\.\d{1,3}){3}|\]|localhost):\d+`)
+)
+
+// StripAddresses replaces memory addresses (e.g. 0xc000123456) in msg with
+// "0x?".
+//
+// It can be used as a normalizer for NormalizedString.
+func StripAddresses(msg string) string {
+	return addressRegexp.ReplaceAllString(msg, "0x?")
+}
+
+// StripPorts replaces the ports of IP addresses and localhost in msg with
+// "?", e.g. "127.0.0.1:54321" becomes "127.0.0.1:?".
+//
+// It can be used as a normalizer for NormalizedString.
+func StripPorts(msg string) string {
+	return portRegexp.ReplaceAllString(msg, "$1:?")
+}
+
+// NormalizedString returns a deterministic version of Error,
+// mainly for comparing against golden files in tests.
+//
+// The messages of the underlying error(s) are first passed through
+// normalizers in order (e.g. StripAddresses and StripPorts),
+// then sorted and deduplicated,
+// so the result does not depend on the order the errors were added,
+// or volatile parts of the messages.
+// The count in the header is the number of deduplicated messages.
+func (eb *ErrBatch) NormalizedString(normalizers ...func(string) string) string {
+	msgs := make([]string, 0, len(eb.entries))
+	for _, e := range eb.entries {
+		msg := errorMessage(e.err)
+		for _, normalize := range normalizers {
+			msg = normalize(msg)
+		}
+		msgs = append(msgs, msg)
+	}
+	slices.Sort(msgs)
+	msgs = slices.Compact(msgs)
+
+	normalized := ErrBatch{
+		entries: make([]entry, len(msgs)),
+		opts:    eb.opts,
+	}
+	for i, msg := range msgs {
+		normalized.entries[i].err = errors.New(msg)
+	}
+	return normalized.Error()
+}
+
+// Canonicalize returns a copy of the batch with the underlying error(s)
+// sorted by their messages,
+// and errors with the same message as a previous one removed.
+//
+// It's useful to compare batches built concurrently,
+// where the order of errors depends on scheduling,
+// for example with errors.Is,
+// or by comparing their Error strings.
+func (eb *ErrBatch) Canonicalize() *ErrBatch {
+	type keyed struct {
+		msg string
+		e   entry
+	}
+	sorted := make([]keyed, len(eb.entries))
+	for i, e := range eb.entries {
+		sorted[i] = keyed{msg: errorMessage(e.err), e: e}
+	}
+	slices.SortStableFunc(sorted, func(a, b keyed) int {
+		return strings.Compare(a.msg, b.msg)
+	})
+	sorted = slices.CompactFunc(sorted, func(a, b keyed) bool {
+		return a.msg == b.msg
+	})
+
+	canonical := &ErrBatch{
+		entries: make([]entry, len(sorted)),
+		opts:    eb.opts,
+	}
+	for i, k := range sorted {
+		canonical.entries[i] = k.e
+	}
+	return canonical
+}
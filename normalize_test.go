@@ -0,0 +1,75 @@
+package errbatch_test
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/fishy/errbatch"
+)
+
+func TestNormalizedString(t *testing.T) {
+	var batch errbatch.ErrBatch
+	if actual := batch.NormalizedString(); actual != "errbatch: total 0 error(s) in this batch" {
+		t.Errorf("Unexpected empty batch string %q", actual)
+	}
+
+	batch.Add(errors.New("foo"))
+	batch.Add(errors.New("dial tcp 127.0.0.1:54321: connection refused"))
+	batch.Add(errors.New("bar"))
+	batch.Add(errors.New("foo"))
+	batch.Add(fmt.Errorf("object at %p", &batch))
+	batch.Add(errors.New("dial tcp 127.0.0.1:12345: connection refused"))
+
+	const expected = "errbatch: total 4 error(s) in this batch: " +
+		"bar; dial tcp 127.0.0.1:?: connection refused; foo; object at 0x?"
+	actual := batch.NormalizedString(errbatch.StripAddresses, errbatch.StripPorts)
+	if actual != expected {
+		t.Errorf("Expected %q, got %q", expected, actual)
+	}
+
+	const unstripped = "errbatch: total 2 error(s) in this batch: bar; foo"
+	batch.Clear()
+	batch.Add(errors.New("foo"))
+	batch.Add(errors.New("bar"))
+	if actual := batch.NormalizedString(); actual != unstripped {
+		t.Errorf("Expected %q, got %q", unstripped, actual)
+	}
+}
+
+func TestStripPorts(t *testing.T) {
+	for _, c := range []struct {
+		msg, expected string
+	}{
+		{"127.0.0.1:8080", "127.0.0.1:?"},
+		{"[::1]:8080", "[::1]:?"},
+		{"localhost:8080", "localhost:?"},
+		{"file.go:42", "file.go:42"},
+	} {
+		if actual := errbatch.StripPorts(c.msg); actual != c.expected {
+			t.Errorf("StripPorts(%q) expected %q, got %q", c.msg, c.expected, actual)
+		}
+	}
+}
+
+func TestCanonicalize(t *testing.T) {
+	err0 := errors.New("foo")
+	err1 := errors.New("bar")
+	a := errbatch.FromSlice([]error{err0, err1, errors.New("foo")})
+	b := errbatch.FromSlice([]error{err1, err0})
+
+	expected := []error{err1, err0}
+	if actual := a.Canonicalize().GetErrors(); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %#v, got %#v", expected, actual)
+	}
+	if actual := a.GetErrors(); len(actual) != 3 {
+		t.Errorf("Expected the original batch to be unchanged, got %#v", actual)
+	}
+	if !errors.Is(a.Canonicalize(), b.Canonicalize()) {
+		t.Errorf("Expected %v to match %v", a.Canonicalize(), b.Canonicalize())
+	}
+	if a.Canonicalize().Error() != b.Canonicalize().Error() {
+		t.Errorf("Expected %q, got %q", b.Canonicalize().Error(), a.Canonicalize().Error())
+	}
+}
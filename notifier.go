@@ -0,0 +1,86 @@
+package errbatch
+
+import (
+	"sync"
+)
+
+// Notifier delivers the final error of a batch to registered listeners,
+// so multiple subsystems (e.g. logging, metrics, alerting) each receive the
+// compiled batch exactly once.
+//
+// The error is delivered by Notify, or by the first compile of a batch
+// created with WithNotifier (via any of Compile, TryCompile, CompileValue,
+// CompileStd, CompilePriority, CompileIf, CompileIfWeight or Builder.Compile).
+//
+// The zero value of Notifier is valid and ready to use.
+// A Notifier must not be copied after first use.
+type Notifier struct {
+	mu        sync.Mutex
+	listeners []func(error)
+	notified  bool
+	err       error
+}
+
+// WithNotifier makes the first compile of the batch deliver the compiled
+// error (including nil) to the listeners of n.
+// All the compile variants notify: Compile, TryCompile, CompileValue,
+// CompileStd, CompilePriority, CompileIf, CompileIfWeight and Builder.Compile
+// (and the Compile of ConcurrentErrBatch and Results created with it).
+//
+// Only the first compile notifies,
+// as n delivers to each listener exactly once.
+func WithNotifier(n *Notifier) Option {
+	return func(o *options) {
+		o.notifier = n
+	}
+}
+
+// Listen registers fn to be called with the final error.
+//
+// If the error was already delivered, fn is called immediately.
+// fn is called on the goroutine delivering the error, so it should not block.
+func (n *Notifier) Listen(fn func(error)) {
+	n.mu.Lock()
+	if !n.notified {
+		n.listeners = append(n.listeners, fn)
+		n.mu.Unlock()
+		return
+	}
+	err := n.err
+	n.mu.Unlock()
+	fn(err)
+}
+
+// Chan returns a channel receiving the final error,
+// which is closed after the error is received.
+func (n *Notifier) Chan() <-chan error {
+	ch := make(chan error, 1)
+	n.Listen(func(err error) {
+		ch <- err
+		close(ch)
+	})
+	return ch
+}
+
+// Notify delivers err to all the listeners,
+// and reports whether it's delivered.
+//
+// Only the first call delivers,
+// later calls are ignored and return false.
+func (n *Notifier) Notify(err error) bool {
+	n.mu.Lock()
+	if n.notified {
+		n.mu.Unlock()
+		return false
+	}
+	n.notified = true
+	n.err = err
+	listeners := n.listeners
+	n.listeners = nil
+	n.mu.Unlock()
+
+	for _, fn := range listeners {
+		fn(err)
+	}
+	return true
+}
@@ -0,0 +1,146 @@
+package errbatch_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/fishy/errbatch"
+)
+
+func TestNotifier(t *testing.T) {
+	var n errbatch.Notifier
+	var calls []error
+	n.Listen(func(err error) {
+		calls = append(calls, err)
+	})
+	ch := n.Chan()
+
+	batch := errbatch.New(errbatch.WithNotifier(&n))
+	errFoo := errors.New("foo")
+	batch.Add(errFoo)
+	batch.Compile()
+	batch.Add(errors.New("bar"))
+	batch.Compile()
+
+	if len(calls) != 1 || calls[0] != errFoo {
+		t.Errorf("Expected [%#v], got %#v", errFoo, calls)
+	}
+	if err := <-ch; err != errFoo {
+		t.Errorf("Expected %#v, got %#v", errFoo, err)
+	}
+	if _, ok := <-ch; ok {
+		t.Error("Expected channel to be closed")
+	}
+
+	t.Run("late", func(t *testing.T) {
+		var late error
+		n.Listen(func(err error) {
+			late = err
+		})
+		if late != errFoo {
+			t.Errorf("Expected %#v, got %#v", errFoo, late)
+		}
+	})
+
+	t.Run("notify", func(t *testing.T) {
+		var n errbatch.Notifier
+		ch := n.Chan()
+		if !n.Notify(nil) {
+			t.Error("Expected first Notify to deliver")
+		}
+		if n.Notify(errFoo) {
+			t.Error("Expected second Notify not to deliver")
+		}
+		if err := <-ch; err != nil {
+			t.Errorf("Expected nil, got %#v", err)
+		}
+	})
+}
+
+func TestNotifierCompileVariants(t *testing.T) {
+	errFoo := errors.New("foo")
+	for _, c := range []struct {
+		label   string
+		compile func(batch *errbatch.ErrBatch) error
+		nilErr  bool
+	}{
+		{
+			label:   "Compile",
+			compile: (*errbatch.ErrBatch).Compile,
+		},
+		{
+			label: "TryCompile",
+			compile: func(batch *errbatch.ErrBatch) error {
+				err, _ := batch.TryCompile()
+				return err
+			},
+		},
+		{
+			label:   "CompileValue",
+			compile: (*errbatch.ErrBatch).CompileValue,
+		},
+		{
+			label:   "CompileStd",
+			compile: (*errbatch.ErrBatch).CompileStd,
+		},
+		{
+			label: "CompilePriority",
+			compile: func(batch *errbatch.ErrBatch) error {
+				return batch.CompilePriority(func(error) int {
+					return 0
+				})
+			},
+		},
+		{
+			label: "CompileIf",
+			compile: func(batch *errbatch.ErrBatch) error {
+				return batch.CompileIf(func([]error) bool {
+					return false
+				})
+			},
+			nilErr: true,
+		},
+		{
+			label: "CompileIfWeight",
+			compile: func(batch *errbatch.ErrBatch) error {
+				return batch.CompileIfWeight(3)
+			},
+			nilErr: true,
+		},
+	} {
+		t.Run(c.label, func(t *testing.T) {
+			var n errbatch.Notifier
+			ch := n.Chan()
+			batch := errbatch.New(errbatch.WithNotifier(&n))
+			batch.Add(errFoo)
+			batch.Add(errors.New("bar"))
+			err := c.compile(batch)
+			if c.nilErr && err != nil {
+				t.Errorf("Expected nil, got %#v", err)
+			}
+			select {
+			case notified := <-ch:
+				if !reflect.DeepEqual(notified, err) {
+					t.Errorf("Expected %#v, got %#v", err, notified)
+				}
+			default:
+				t.Error("Expected the Notifier to be notified")
+			}
+		})
+	}
+
+	t.Run("Builder", func(t *testing.T) {
+		var n errbatch.Notifier
+		ch := n.Chan()
+		err := errbatch.NewBuilder(errbatch.WithNotifier(&n)).Add(errFoo).Compile()
+		select {
+		case notified := <-ch:
+			if !reflect.DeepEqual(notified, err) {
+				t.Errorf("Expected %#v, got %#v", err, notified)
+			}
+		default:
+			t.Error("Expected the Notifier to be notified")
+		}
+	})
+}
@@ -0,0 +1,121 @@
+package errbatch
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// Option configures a batch created by New.
+type Option func(*options)
+
+type options struct {
+	header          func(count int) string
+	headerMin       int
+	separator       string
+	maxDisplayed    int
+	maxMessageBytes int
+	redactor        func(msg string) string
+	formatter       Formatter
+	typeNames       bool
+	wrapChain       bool
+	chainDepth      int
+	lossless        bool
+
+	traceExtractor func(ctx context.Context) string
+
+	caller     bool
+	callerSkip int
+	stack      bool
+	debug      bool
+
+	sequenceOrder   bool
+	timestamps      bool
+	recursive       bool
+	preserveWrapped bool
+	noSingleUnwrap  bool
+
+	dedup  DedupFunc
+	ignore []error
+
+	aggregation Aggregation
+
+	cancelOnError bool
+	notifier      *Notifier
+
+	// maxErrors is the max number of errors retained by the batch,
+	// 0 means unlimited.
+	// rank is set by WithTopN, and fingerprint by WithUniqueLRU,
+	// otherwise the earliest added errors are evicted.
+	maxErrors int
+	rank      func(error) int
+
+	fingerprint func(error) string
+}
+
+// builtinOptions are the options used when SetDefaults is never called.
+var builtinOptions = options{
+	header:    defaultHeader,
+	separator: "; ",
+}
+
+// defaultOptions are the options set by SetDefaults.
+var defaultOptions atomic.Pointer[options]
+
+// SetDefaults sets the default options,
+// used by all batches created after it's called via New (and the other
+// constructors taking options), and all zero value batches.
+//
+// Options passed to New are applied on top of the defaults.
+// Calling SetDefaults without any options restores the built-in defaults.
+//
+// It's intended to be called once at the start of the application,
+// for example:
+//
+//	func main() {
+//		errbatch.SetDefaults(
+//			errbatch.WithSeparator("\n"),
+//			errbatch.WithMaxDisplayed(10),
+//			errbatch.WithRedactor(redactSecrets),
+//		)
+//		// ...
+//	}
+//
+// Zero value batches created before SetDefaults is called also use the new
+// defaults, but batches created by New keep the defaults at their creation.
+func SetDefaults(opts ...Option) {
+	o := builtinOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	defaultOptions.Store(&o)
+}
+
+// getDefaultOptions returns the options set by SetDefaults,
+// or the built-in options.
+func getDefaultOptions() *options {
+	if o := defaultOptions.Load(); o != nil {
+		return o
+	}
+	return &builtinOptions
+}
+
+// New creates a new batch with the given options.
+//
+// A batch created by New without any options behaves the same as the zero
+// value of ErrBatch.
+func New(opts ...Option) *ErrBatch {
+	o := *getDefaultOptions()
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return &ErrBatch{
+		opts: &o,
+	}
+}
+
+func (eb *ErrBatch) getOptions() *options {
+	if eb.opts != nil {
+		return eb.opts
+	}
+	return getDefaultOptions()
+}
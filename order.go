@@ -0,0 +1,55 @@
+package errbatch
+
+import (
+	"cmp"
+	"slices"
+	"sync/atomic"
+)
+
+// entrySeq is the last sequence number assigned to an entry.
+var entrySeq atomic.Uint64
+
+func nextSeq() uint64 {
+	return entrySeq.Add(1)
+}
+
+// WithSequenceOrder makes the batch order its errors by the sequence they
+// were first added into any batch created with WithSequenceOrder in the
+// process,
+// instead of the order they were added into this batch.
+//
+// It's useful when merging per-goroutine batches,
+// also created with WithSequenceOrder,
+// into a single batch:
+//
+//	merged := errbatch.New(errbatch.WithSequenceOrder())
+//	for _, batch := range perGoroutineBatches {
+//		merged.Add(batch)
+//	}
+//
+// The errors in merged will be in the same order as they were added into the
+// per-goroutine batches,
+// regardless of the order the per-goroutine batches are merged.
+//
+// Assigning the sequence numbers uses a process-wide atomic counter,
+// so errors added into batches created without WithSequenceOrder don't have
+// them, and are ordered before the ones with sequence numbers,
+// keeping their relative order.
+func WithSequenceOrder() Option {
+	return func(o *options) {
+		o.sequenceOrder = true
+	}
+}
+
+// sortBySeq sorts the entries by sequence when WithSequenceOrder is used.
+//
+// It's only called after entries from other batches are appended,
+// as new entries always have the largest sequence numbers.
+func (eb *ErrBatch) sortBySeq() {
+	if eb.getOptions().sequenceOrder {
+		slices.SortStableFunc(eb.entries, func(a, b entry) int {
+			return cmp.Compare(a.getMeta().seq, b.getMeta().seq)
+		})
+		eb.ret = nil
+	}
+}
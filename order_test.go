@@ -0,0 +1,59 @@
+package errbatch_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/fishy/errbatch"
+)
+
+func TestSequenceOrder(t *testing.T) {
+	err0 := errors.New("foo")
+	err1 := errors.New("bar")
+	err2 := errors.New("foobar")
+	err3 := errors.New("barfoo")
+
+	batch0 := errbatch.New(errbatch.WithSequenceOrder())
+	batch1 := errbatch.New(errbatch.WithSequenceOrder())
+	batch0.Add(err0)
+	batch1.Add(err1)
+	batch0.Add(err2)
+	batch1.Add(err3)
+
+	merged := errbatch.New(errbatch.WithSequenceOrder())
+	merged.Add(batch1)
+	merged.Add(batch0)
+	expected := []error{err0, err1, err2, err3}
+	if errs := merged.GetErrors(); !reflect.DeepEqual(errs, expected) {
+		t.Errorf("Expected %v, got %v", expected, errs)
+	}
+
+	var unordered errbatch.ErrBatch
+	unordered.Add(batch1)
+	unordered.Add(batch0)
+	expected = []error{err1, err3, err0, err2}
+	if errs := unordered.GetErrors(); !reflect.DeepEqual(errs, expected) {
+		t.Errorf("Expected %v, got %v", expected, errs)
+	}
+}
+
+func TestSequenceOrderUnassigned(t *testing.T) {
+	err0 := errors.New("foo")
+	err1 := errors.New("bar")
+	err2 := errors.New("foobar")
+
+	ordered := errbatch.New(errbatch.WithSequenceOrder())
+	ordered.Add(err0)
+	var plain errbatch.ErrBatch
+	plain.Add(err1)
+	plain.Add(err2)
+
+	merged := errbatch.New(errbatch.WithSequenceOrder())
+	merged.Add(ordered)
+	merged.Add(&plain)
+	expected := []error{err1, err2, err0}
+	if errs := merged.GetErrors(); !reflect.DeepEqual(errs, expected) {
+		t.Errorf("Expected %v, got %v", expected, errs)
+	}
+}
@@ -0,0 +1,50 @@
+package errbatch
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var defaultMessageRegexp = regexp.MustCompile(
+	`(?s)^errbatch: total (\d+) error\(s\) in this batch(?:: (.*))?$`,
+)
+
+// Parse reconstructs a batch from s,
+// the message of an ErrBatch with the default header
+// (e.g. an error that crossed a process boundary as text).
+//
+// The underlying errors of the returned batch are created by errors.New,
+// so only their messages are kept.
+//
+// It's best-effort:
+// it returns false if s is not in the format,
+// or if the messages cannot be split unambiguously
+// (e.g. one of them contains "; ").
+func Parse(s string) (*ErrBatch, bool) {
+	groups := defaultMessageRegexp.FindStringSubmatch(s)
+	if groups == nil {
+		return nil, false
+	}
+	count, err := strconv.Atoi(groups[1])
+	if err != nil {
+		return nil, false
+	}
+
+	var msgs []string
+	if count > 0 {
+		msgs = strings.Split(groups[2], "; ")
+	} else if groups[2] != "" {
+		return nil, false
+	}
+	if len(msgs) != count {
+		return nil, false
+	}
+
+	batch := new(ErrBatch)
+	for _, msg := range msgs {
+		batch.Add(errors.New(msg))
+	}
+	return batch, true
+}
@@ -0,0 +1,68 @@
+package errbatch_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/fishy/errbatch"
+)
+
+func TestParse(t *testing.T) {
+	for _, c := range []struct {
+		label    string
+		s        string
+		ok       bool
+		expected []string
+	}{
+		{
+			label: "empty",
+			s:     new(errbatch.ErrBatch).Error(),
+			ok:    true,
+		},
+		{
+			label:    "batch",
+			s:        errbatch.FromSlice([]error{errors.New("foo"), errors.New("bar")}).Error(),
+			ok:       true,
+			expected: []string{"foo", "bar"},
+		},
+		{
+			label:    "multiline",
+			s:        errbatch.FromSlice([]error{errors.New("foo\nbar")}).Error(),
+			ok:       true,
+			expected: []string{"foo\nbar"},
+		},
+		{
+			label: "ambiguous",
+			s:     errbatch.FromSlice([]error{errors.New("foo; bar")}).Error(),
+		},
+		{
+			label: "not-batch",
+			s:     "foo",
+		},
+		{
+			label: "empty-with-messages",
+			s:     "errbatch: total 0 error(s) in this batch: foo",
+		},
+	} {
+		t.Run(c.label, func(t *testing.T) {
+			batch, ok := errbatch.Parse(c.s)
+			if ok != c.ok {
+				t.Fatalf("Expected ok to be %v, got %v", c.ok, ok)
+			}
+			if !ok {
+				return
+			}
+			var msgs []string
+			for _, err := range batch.GetErrors() {
+				msgs = append(msgs, err.Error())
+			}
+			if !reflect.DeepEqual(msgs, c.expected) {
+				t.Errorf("Expected %q, got %q", c.expected, msgs)
+			}
+			if actual := batch.Error(); actual != c.s {
+				t.Errorf("Expected round trip to %q, got %q", c.s, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,24 @@
+package errbatch
+
+// CompileIf compiles the batch the same as Compile,
+// but only when pred reports that the errors in the batch constitute a
+// failure.
+// Otherwise it returns nil,
+// which is also delivered to the Notifier set by WithNotifier.
+//
+// pred is only called when the batch contains at least one error,
+// with a copy of the underlying errors.
+//
+// For example, to fail only when any of the errors is not retryable:
+//
+//	return batch.CompileIf(func(errs []error) bool {
+//		return slices.ContainsFunc(errs, func(err error) bool {
+//			return !isRetryable(err)
+//		})
+//	})
+func (eb *ErrBatch) CompileIf(pred func(errs []error) bool) error {
+	if len(eb.entries) == 0 || !pred(eb.GetErrors()) {
+		return eb.notify(nil)
+	}
+	return eb.Compile()
+}
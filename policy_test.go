@@ -0,0 +1,38 @@
+package errbatch_test
+
+import (
+	"errors"
+	"io"
+	"slices"
+	"testing"
+
+	"github.com/fishy/errbatch"
+)
+
+func TestCompileIf(t *testing.T) {
+	called := false
+	nonRetryable := func(errs []error) bool {
+		called = true
+		return slices.ContainsFunc(errs, func(err error) bool {
+			return !errors.Is(err, io.EOF)
+		})
+	}
+
+	var batch errbatch.ErrBatch
+	if err := batch.CompileIf(nonRetryable); err != nil {
+		t.Errorf("Expected nil for empty batch, got %#v", err)
+	}
+	if called {
+		t.Error("Expected pred to not be called on empty batch")
+	}
+
+	batch.Add(io.EOF)
+	if err := batch.CompileIf(nonRetryable); err != nil {
+		t.Errorf("Expected nil, got %#v", err)
+	}
+
+	batch.Add(errors.New("foo"))
+	if err := batch.CompileIf(nonRetryable); err == nil {
+		t.Error("Expected non-nil error")
+	}
+}
@@ -0,0 +1,66 @@
+package errbatch
+
+import (
+	"fmt"
+)
+
+// CompilePriority compiles the batch into its single most severe error.
+//
+// rank returns the severity of an error, higher is more severe.
+// Among errors with the same rank, the one added first wins.
+//
+// If the batch contains zero errors, it will return nil.
+//
+// If the batch contains exactly one error,
+// that underlying error will be returned.
+//
+// Otherwise, the returned error has the same message as the most severe
+// error,
+// and all the errors in the batch are still reachable via Unwrap,
+// so errors.Is and errors.As can check the rest of them as well.
+//
+// Like Compile, it delivers the result to the Notifier set by WithNotifier.
+func (eb *ErrBatch) CompilePriority(rank func(error) int) error {
+	countCompiled()
+	switch len(eb.entries) {
+	case 0:
+		return eb.notify(nil)
+	case 1:
+		return eb.notify(eb.entries[0].err)
+	}
+
+	primary := 0
+	best := rank(eb.entries[0].err)
+	for i, e := range eb.entries[1:] {
+		if r := rank(e.err); r > best {
+			primary, best = i+1, r
+		}
+	}
+	errs := eb.GetErrors()
+	return eb.notify(&priorityError{
+		primary: errs[primary],
+		others:  append(errs[:primary:primary], errs[primary+1:]...),
+	})
+}
+
+// priorityError is returned by CompilePriority.
+type priorityError struct {
+	primary error
+	others  []error
+}
+
+func (pe *priorityError) Error() string {
+	return pe.primary.Error()
+}
+
+// Format forwards formatting to the primary error.
+func (pe *priorityError) Format(s fmt.State, verb rune) {
+	fmt.Fprintf(s, fmt.FormatString(s, verb), pe.primary)
+}
+
+// Unwrap returns the primary error followed by the rest of the errors.
+func (pe *priorityError) Unwrap() []error {
+	errs := make([]error, 0, len(pe.others)+1)
+	errs = append(errs, pe.primary)
+	return append(errs, pe.others...)
+}
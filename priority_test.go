@@ -0,0 +1,53 @@
+package errbatch_test
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/fishy/errbatch"
+)
+
+func TestCompilePriority(t *testing.T) {
+	rank := func(err error) int {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return 2
+		}
+		if errors.Is(err, io.EOF) {
+			return 1
+		}
+		return 0
+	}
+
+	var batch errbatch.ErrBatch
+	if err := batch.CompilePriority(rank); err != nil {
+		t.Errorf("Expected nil, got %#v", err)
+	}
+
+	err0 := errors.New("foo")
+	batch.Add(err0)
+	if err := batch.CompilePriority(rank); err != err0 {
+		t.Errorf("Expected %#v, got %#v", err0, err)
+	}
+
+	batch.Add(io.EOF)
+	batch.Add(io.ErrUnexpectedEOF)
+	batch.Add(errors.New("bar"))
+	err := batch.CompilePriority(rank)
+	if err.Error() != io.ErrUnexpectedEOF.Error() {
+		t.Errorf("Expected %q, got %q", io.ErrUnexpectedEOF.Error(), err.Error())
+	}
+	for _, target := range []error{err0, io.EOF, io.ErrUnexpectedEOF} {
+		if !errors.Is(err, target) {
+			t.Errorf("Expected errors.Is(%v, %v) to be true", err, target)
+		}
+	}
+	if len(batch.GetErrors()) != 4 {
+		t.Errorf("Expected the batch to be unchanged, got %#v", batch.GetErrors())
+	}
+
+	constant := func(error) int { return 0 }
+	if err := batch.CompilePriority(constant); err.Error() != err0.Error() {
+		t.Errorf("Expected the first error %q for ties, got %q", err0.Error(), err.Error())
+	}
+}
@@ -0,0 +1,59 @@
+package errbatch
+
+import (
+	"time"
+)
+
+// ErrorRecord is the structured view of each of the underlying errors of a
+// batch, as returned by Records.
+type ErrorRecord struct {
+	// The message of the error, redacted by WithRedactor.
+	Message string
+
+	// The type name of the error, e.g. "*errors.errorString".
+	TypeName string
+
+	// The stack captured when the error was added (see WithStack),
+	// one "function file:line" string per frame.
+	// It's nil if the stack was not captured.
+	Stack []string
+
+	// The tags of the error, see TagCode, TagName and TagTraceID.
+	// It's nil if the error has no tags.
+	Tags map[string]string
+
+	// The time the error was first added into any batch created with
+	// WithTimestamps.
+	// It's zero if unknown (e.g. decoded by UnmarshalBinary).
+	Timestamp time.Time
+}
+
+// WithTimestamps makes the batch record the time each error is added,
+// reported as the Timestamp of Records.
+//
+// It's not enabled by default to keep Add cheap.
+func WithTimestamps() Option {
+	return func(o *options) {
+		o.timestamps = true
+	}
+}
+
+// Records returns the structured view of the underlying errors of the batch,
+// in the same order as GetErrors.
+//
+// It's intended for observability integrations,
+// so they don't need to reflect over GetErrors themselves.
+func (eb *ErrBatch) Records() []ErrorRecord {
+	opts := eb.getOptions()
+	records := make([]ErrorRecord, len(eb.entries))
+	for i, e := range eb.entries {
+		records[i] = ErrorRecord{
+			Message:   opts.redact(errorMessage(e.err)),
+			TypeName:  typeName(e.err),
+			Stack:     e.stackStrings(),
+			Tags:      e.tags(),
+			Timestamp: e.getMeta().at,
+		}
+	}
+	return records
+}
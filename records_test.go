@@ -0,0 +1,85 @@
+package errbatch_test
+
+import (
+	"errors"
+	"maps"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fishy/errbatch"
+)
+
+func TestRecords(t *testing.T) {
+	before := time.Now()
+	batch := errbatch.New(errbatch.WithStack(), errbatch.WithTimestamps())
+	batch.Add(errors.New("foo"))
+	batch.AddCoded("code", customError{})
+	after := time.Now()
+
+	records := batch.Records()
+	if len(records) != 2 {
+		t.Fatalf("Expected 2 records, got %#v", records)
+	}
+	for i, c := range []struct {
+		message  string
+		typeName string
+		tags     map[string]string
+	}{
+		{
+			message:  "foo",
+			typeName: "*errors.errorString",
+		},
+		{
+			message:  "custom",
+			typeName: "errbatch_test.customError",
+			tags:     map[string]string{errbatch.TagCode: "code"},
+		},
+	} {
+		r := records[i]
+		if r.Message != c.message {
+			t.Errorf("%d: Expected message %q, got %q", i, c.message, r.Message)
+		}
+		if r.TypeName != c.typeName {
+			t.Errorf("%d: Expected type name %q, got %q", i, c.typeName, r.TypeName)
+		}
+		if !maps.Equal(r.Tags, c.tags) {
+			t.Errorf("%d: Expected tags %#v, got %#v", i, c.tags, r.Tags)
+		}
+		if len(r.Stack) == 0 || !strings.Contains(r.Stack[0], "TestRecords") {
+			t.Errorf("%d: Expected stack starting with TestRecords, got %#v", i, r.Stack)
+		}
+		if r.Timestamp.Before(before) || r.Timestamp.After(after) {
+			t.Errorf("%d: Expected timestamp between %v and %v, got %v", i, before, after, r.Timestamp)
+		}
+	}
+
+	t.Run("decoded", func(t *testing.T) {
+		data, err := batch.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var decoded errbatch.ErrBatch
+		if err := decoded.UnmarshalBinary(data); err != nil {
+			t.Fatal(err)
+		}
+		for i, r := range decoded.Records() {
+			if !r.Timestamp.IsZero() {
+				t.Errorf("%d: Expected zero timestamp, got %v", i, r.Timestamp)
+			}
+			if len(r.Stack) == 0 {
+				t.Errorf("%d: Expected stack to be kept", i)
+			}
+		}
+	})
+}
+
+func TestRecordsWithoutTimestamps(t *testing.T) {
+	var batch errbatch.ErrBatch
+	batch.Add(errors.New("foo"))
+	for i, r := range batch.Records() {
+		if !r.Timestamp.IsZero() {
+			t.Errorf("%d: Expected zero timestamp, got %v", i, r.Timestamp)
+		}
+	}
+}
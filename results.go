@@ -0,0 +1,181 @@
+package errbatch
+
+import (
+	"strconv"
+	"sync"
+)
+
+// Results collects the per-index outcomes of a slice-based fan-out,
+// including the successful (nil) ones,
+// so callers can retry exactly the failed items.
+//
+// It's safe to call Set concurrently from multiple goroutines.
+//
+// The zero value of Results is valid and ready to use.
+// A Results must not be copied after first use.
+type Results struct {
+	mu   sync.Mutex
+	errs []error
+	opts []Option
+}
+
+// NewResults creates a new Results with n slots,
+// and the given options used by the batch returned by Compile.
+func NewResults(n int, opts ...Option) *Results {
+	return &Results{
+		errs: make([]error, n),
+		opts: opts,
+	}
+}
+
+// Set records the outcome of the i-th item, nil means it succeeded.
+//
+// The slots grow as needed when i is beyond the current length.
+// Setting the same i again overrides the previous outcome.
+// It panics when i is negative.
+func (r *Results) Set(i int, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if i >= len(r.errs) {
+		r.errs = append(r.errs, make([]error, i+1-len(r.errs))...)
+	}
+	r.errs[i] = err
+}
+
+// Len returns the number of slots.
+func (r *Results) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.errs)
+}
+
+// Err returns the outcome of the i-th item,
+// or nil if it succeeded or was never set.
+func (r *Results) Err(i int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if i < 0 || i >= len(r.errs) {
+		return nil
+	}
+	return r.errs[i]
+}
+
+// Failed returns the indices of the failed items, in ascending order.
+func (r *Results) Failed() []int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var failed []int
+	for i, err := range r.errs {
+		if err != nil {
+			failed = append(failed, i)
+		}
+	}
+	return failed
+}
+
+// Compile compiles the errors of the failed items into a batch,
+// each labeled with its index (as if added via ErrBatch.AddNamed),
+// e.g. "errbatch: total 2 error(s) in this batch: 1: timeout; 3: EOF".
+//
+// See ErrBatch.Compile for details of the returned error.
+func (r *Results) Compile() error {
+	return r.batch().Compile()
+}
+
+// batch returns a new batch of the errors of the failed items,
+// each labeled with its index.
+func (r *Results) batch() *ErrBatch {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	batch := New(r.opts...)
+	for i, err := range r.errs {
+		batch.AddNamed(strconv.Itoa(i), err)
+	}
+	return batch
+}
+
+// ResultsOf collects the (index, value, error) triples of a slice-based
+// parallel fan-out,
+// pairing the values with the compiled batch of the errors.
+//
+// It's safe to call Set concurrently from multiple goroutines.
+//
+// The zero value of ResultsOf is valid and ready to use.
+// A ResultsOf must not be copied after first use.
+//
+// Example:
+//
+//	results := errbatch.NewResultsOf[*User](len(ids))
+//	var wg sync.WaitGroup
+//	for i, id := range ids {
+//		wg.Add(1)
+//		go func() {
+//			defer wg.Done()
+//			user, err := fetchUser(ctx, id)
+//			results.Set(i, user, err)
+//		}()
+//	}
+//	wg.Wait()
+//	return results.Compile()
+type ResultsOf[T any] struct {
+	results Results
+
+	mu     sync.Mutex
+	values []T
+}
+
+// NewResultsOf creates a new ResultsOf with n slots,
+// and the given options used by the batch returned by Errors and Compile.
+func NewResultsOf[T any](n int, opts ...Option) *ResultsOf[T] {
+	return &ResultsOf[T]{
+		results: Results{
+			errs: make([]error, n),
+			opts: opts,
+		},
+		values: make([]T, n),
+	}
+}
+
+// Set records the value and error of the i-th item.
+//
+// See Results.Set for details.
+func (r *ResultsOf[T]) Set(i int, value T, err error) {
+	r.mu.Lock()
+	if i >= len(r.values) {
+		r.values = append(r.values, make([]T, i+1-len(r.values))...)
+	}
+	r.values[i] = value
+	r.mu.Unlock()
+
+	r.results.Set(i, err)
+}
+
+// Failed returns the indices of the failed items, in ascending order.
+func (r *ResultsOf[T]) Failed() []int {
+	return r.results.Failed()
+}
+
+// Values returns a copy of the values of all the items, indexed by their
+// indices.
+//
+// The values of the failed items are whatever were set with the errors
+// (usually zero values), and the ones never set are zero values.
+func (r *ResultsOf[T]) Values() []T {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	values := make([]T, max(len(r.values), r.results.Len()))
+	copy(values, r.values)
+	return values
+}
+
+// Errors returns a new batch of the errors of the failed items,
+// each labeled with its index (see Results.Compile).
+func (r *ResultsOf[T]) Errors() *ErrBatch {
+	return r.results.batch()
+}
+
+// Compile returns the values (see Values) and the compiled batch of the errors
+// (see Results.Compile).
+func (r *ResultsOf[T]) Compile() ([]T, error) {
+	return r.Values(), r.results.Compile()
+}
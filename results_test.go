@@ -0,0 +1,108 @@
+package errbatch_test
+
+import (
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/fishy/errbatch"
+)
+
+func TestResults(t *testing.T) {
+	r := errbatch.NewResults(4)
+	var wg sync.WaitGroup
+	for i := range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var err error
+			if i%2 == 1 {
+				err = errors.New("odd")
+			}
+			r.Set(i, err)
+		}()
+	}
+	wg.Wait()
+
+	if actual, expected := r.Failed(), []int{1, 3}; !slices.Equal(actual, expected) {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+	if err := r.Err(0); err != nil {
+		t.Errorf("Expected nil for succeeded item, got %#v", err)
+	}
+	if err := r.Err(10); err != nil {
+		t.Errorf("Expected nil for out of range item, got %#v", err)
+	}
+	const expected = "errbatch: total 2 error(s) in this batch: 1: odd; 3: odd"
+	if err := r.Compile(); err == nil || err.Error() != expected {
+		t.Errorf("Expected %q, got %v", expected, err)
+	}
+
+	t.Run("grow", func(t *testing.T) {
+		var r errbatch.Results
+		r.Set(2, errors.New("foo"))
+		if actual := r.Len(); actual != 3 {
+			t.Errorf("Expected 3 slots, got %d", actual)
+		}
+		if err := r.Compile(); err == nil || err.Error() != "2: foo" {
+			t.Errorf("Expected %q, got %v", "2: foo", err)
+		}
+	})
+
+	t.Run("succeeded", func(t *testing.T) {
+		r := errbatch.NewResults(2)
+		r.Set(0, nil)
+		r.Set(1, nil)
+		if failed := r.Failed(); len(failed) != 0 {
+			t.Errorf("Expected no failures, got %v", failed)
+		}
+		if err := r.Compile(); err != nil {
+			t.Errorf("Expected nil, got %#v", err)
+		}
+	})
+}
+
+func TestResultsOf(t *testing.T) {
+	inputs := []int{1, 2, 3, 4}
+	r := errbatch.NewResultsOf[int](len(inputs))
+	var wg sync.WaitGroup
+	for i, input := range inputs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if input == 3 {
+				r.Set(i, 0, errors.New("three"))
+				return
+			}
+			r.Set(i, input*10, nil)
+		}()
+	}
+	wg.Wait()
+
+	values, err := r.Compile()
+	if expected := []int{10, 20, 0, 40}; !slices.Equal(values, expected) {
+		t.Errorf("Expected %v, got %v", expected, values)
+	}
+	if err == nil || err.Error() != "2: three" {
+		t.Errorf("Expected %q, got %v", "2: three", err)
+	}
+	if actual, expected := r.Failed(), []int{2}; !slices.Equal(actual, expected) {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+	if errs := r.Errors().GetErrors(); len(errs) != 1 {
+		t.Errorf("Expected 1 error, got %#v", errs)
+	}
+
+	t.Run("zero", func(t *testing.T) {
+		var r errbatch.ResultsOf[string]
+		r.Set(1, "foo", nil)
+		values, err := r.Compile()
+		if expected := []string{"", "foo"}; !slices.Equal(values, expected) {
+			t.Errorf("Expected %q, got %q", expected, values)
+		}
+		if err != nil {
+			t.Errorf("Expected nil, got %#v", err)
+		}
+	})
+}
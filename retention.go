@@ -0,0 +1,298 @@
+package errbatch
+
+import (
+	"cmp"
+	"container/heap"
+	"slices"
+)
+
+// WithTopN makes the batch retain only the n most important errors,
+// ranked by rank (higher is more important),
+// so when more than n errors are added,
+// the fatal ones are kept and the noise is dropped,
+// instead of blindly keeping the first n errors.
+//
+// Among errors with the same rank, the ones added first are kept.
+// The retained errors keep the order they were added in.
+// The number of evicted errors can be retrieved via Dropped.
+//
+// rank is called once on each added error.
+//
+// Only one retention policy can be used,
+// WithUniqueLRU, WithRingBuffer and WithTopN override each other.
+// ConcurrentErrBatch evicts errors as they are added,
+// with Add serialized by a mutex.
+//
+// n <= 0 disables the retention limit.
+func WithTopN(n int, rank func(error) int) Option {
+	return func(o *options) {
+		o.maxErrors = n
+		o.rank = rank
+		o.fingerprint = nil
+	}
+}
+
+// WithRingBuffer makes the batch retain only the n most recently added
+// errors, like a FIFO ring buffer,
+// which suits long-running collectors where the latest failures are more
+// diagnostic than the earliest ones.
+//
+// The number of evicted errors can be retrieved via Dropped.
+//
+// Only one retention policy can be used,
+// WithUniqueLRU, WithRingBuffer and WithTopN override each other.
+// ConcurrentErrBatch evicts errors as they are added,
+// with Add serialized by a mutex.
+//
+// n <= 0 disables the retention limit.
+func WithRingBuffer(n int) Option {
+	return func(o *options) {
+		o.maxErrors = n
+		o.rank = nil
+		o.fingerprint = nil
+	}
+}
+
+// WithUniqueLRU makes the batch retain only one representative error per
+// unique fingerprint, with the number of times errors with the same
+// fingerprint were added (see Counts),
+// and at most n unique errors,
+// evicting the least recently seen ones when there are more,
+// which suits daemons aggregating errors continuously.
+//
+// The representative of each fingerprint is the first error added with it,
+// and the errors are ordered from the least to the most recently seen.
+// Nil fingerprint uses the error messages as the fingerprints.
+// The number of evicted errors (not counting the merged repeats) can be
+// retrieved via Dropped.
+//
+// Only one retention policy can be used,
+// WithUniqueLRU, WithRingBuffer and WithTopN override each other.
+// ConcurrentErrBatch merges and evicts errors as they are added,
+// with Add serialized by a mutex.
+//
+// n <= 0 disables the retention limit, but still merges the errors.
+func WithUniqueLRU(n int, fingerprint func(error) string) Option {
+	if fingerprint == nil {
+		fingerprint = func(err error) string {
+			return errorMessage(err)
+		}
+	}
+	return func(o *options) {
+		WithRingBuffer(n)(o)
+		o.fingerprint = fingerprint
+	}
+}
+
+// Counts returns the number of times each of the underlying errors was
+// added, in the same order as GetErrors.
+//
+// It's always 1 unless the batch is created with WithUniqueLRU,
+// in which case it's the number of times errors with the same fingerprint
+// were added.
+func (eb *ErrBatch) Counts() []int {
+	counts := make([]int, len(eb.entries))
+	for i, e := range eb.entries {
+		counts[i] = e.getMeta().repeats + 1
+	}
+	return counts
+}
+
+// addUnique adds entries into the batch for WithUniqueLRU,
+// merging the ones with the same fingerprint as an existing error into it,
+// and moving it to the end as the most recently seen.
+func (eb *ErrBatch) addUnique(entries []entry, opts *options) {
+	r := eb.retention(opts)
+	for _, e := range entries {
+		fingerprint := opts.fingerprint(e.err)
+		if order, ok := r.orders[fingerprint]; ok {
+			i := eb.indexOf(order)
+			existing := eb.entries[i]
+			existing.editMeta().repeats += e.getMeta().repeats + 1
+			eb.removeAt(i)
+			e = existing
+		} else if opts.maxErrors > 0 && len(eb.entries) >= opts.maxErrors {
+			// Evict the least recently seen error.
+			delete(r.orders, eb.entries[0].getMeta().fingerprint)
+			eb.removeAt(0)
+			eb.drop(1)
+		}
+		meta := e.editMeta()
+		meta.fingerprint = fingerprint
+		meta.order = r.next
+		r.next++
+		r.orders[fingerprint] = meta.order
+		eb.entries = append(eb.entries, e)
+	}
+	r.n = len(eb.entries)
+}
+
+// addRanked adds entries into the batch for WithTopN,
+// evicting the errors with the lowest ranks when the batch is full.
+func (eb *ErrBatch) addRanked(entries []entry, opts *options) {
+	r := eb.retention(opts)
+	for _, e := range entries {
+		rank := opts.rank(e.err)
+		if len(eb.entries) >= opts.maxErrors {
+			// Among errors with the same rank, the ones added first are kept,
+			// so e is evicted unless it outranks the lowest one.
+			if rank <= r.ranks[0].rank {
+				eb.drop(1)
+				continue
+			}
+			lowest := heap.Pop(&r.ranks).(rankedOrder)
+			eb.removeAt(eb.indexOf(lowest.order))
+			eb.drop(1)
+		}
+		meta := e.editMeta()
+		meta.rank = rank
+		meta.order = r.next
+		r.next++
+		heap.Push(&r.ranks, rankedOrder{rank: rank, order: meta.order})
+		eb.entries = append(eb.entries, e)
+	}
+	r.n = len(eb.entries)
+}
+
+// retentionState indexes the entries of a batch for the retention policy,
+// so adding an error does not need to scan all the errors in the batch.
+//
+// The entries are assigned increasing orders as they are appended,
+// so they can be located via binary search.
+type retentionState struct {
+	// n is the number of entries indexed,
+	// the state is rebuilt when it doesn't match the batch.
+	n int
+
+	// next is the order of the next appended entry.
+	next uint64
+
+	// orders maps the fingerprints to the orders of the entries,
+	// for WithUniqueLRU.
+	orders map[string]uint64
+
+	// ranks is a heap of the ranks of the entries with the next one to evict
+	// at the top, for WithTopN.
+	ranks rankHeap
+}
+
+type rankedOrder struct {
+	rank  int
+	order uint64
+}
+
+// rankHeap implements heap.Interface,
+// ordering the lowest rank, and then the latest added one, first.
+type rankHeap []rankedOrder
+
+func (h rankHeap) Len() int {
+	return len(h)
+}
+
+func (h rankHeap) Less(i, j int) bool {
+	if h[i].rank != h[j].rank {
+		return h[i].rank < h[j].rank
+	}
+	return h[i].order > h[j].order
+}
+
+func (h rankHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+}
+
+func (h *rankHeap) Push(x any) {
+	*h = append(*h, x.(rankedOrder))
+}
+
+func (h *rankHeap) Pop() any {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+// retention returns the retentionState of the batch,
+// rebuilding it from the entries if it's out of sync.
+func (eb *ErrBatch) retention(opts *options) *retentionState {
+	if eb.ret != nil && eb.ret.n == len(eb.entries) {
+		return eb.ret
+	}
+	r := &retentionState{
+		n:    len(eb.entries),
+		next: uint64(len(eb.entries)),
+	}
+	if opts.fingerprint != nil {
+		r.orders = make(map[string]uint64, len(eb.entries))
+	}
+	for i := range eb.entries {
+		e := &eb.entries[i]
+		meta := e.editMeta()
+		meta.order = uint64(i)
+		if opts.fingerprint != nil {
+			meta.fingerprint = opts.fingerprint(e.err)
+			r.orders[meta.fingerprint] = meta.order
+		}
+		if opts.rank != nil {
+			meta.rank = opts.rank(e.err)
+			r.ranks = append(r.ranks, rankedOrder{rank: meta.rank, order: meta.order})
+		}
+	}
+	heap.Init(&r.ranks)
+	eb.ret = r
+	return r
+}
+
+// indexOf returns the index of the entry with order in the batch.
+func (eb *ErrBatch) indexOf(order uint64) int {
+	i, found := slices.BinarySearchFunc(eb.entries, order, func(e entry, order uint64) int {
+		return cmp.Compare(e.getMeta().order, order)
+	})
+	if found {
+		return i
+	}
+	return slices.IndexFunc(eb.entries, func(e entry) bool {
+		return e.getMeta().order == order
+	})
+}
+
+// removeAt removes the i-th entry from the batch, keeping the order of the
+// rest.
+func (eb *ErrBatch) removeAt(i int) {
+	copy(eb.entries[i:], eb.entries[i+1:])
+	eb.entries[len(eb.entries)-1] = entry{}
+	eb.entries = eb.entries[:len(eb.entries)-1]
+}
+
+// Dropped returns the number of errors evicted from the batch by the
+// retention policy (WithTopN, WithRingBuffer or WithUniqueLRU) since it's
+// created or last cleared.
+func (eb *ErrBatch) Dropped() int {
+	return eb.dropped
+}
+
+// drop counts n errors evicted from the batch.
+func (eb *ErrBatch) drop(n int) {
+	eb.dropped += n
+	countDropped(n)
+}
+
+// retains returns whether WithDedup or a retention policy is used,
+// which makes ConcurrentErrBatch serialize Add calls.
+func (opts *options) retains() bool {
+	return opts.dedup != nil || opts.maxErrors > 0 || opts.fingerprint != nil
+}
+
+// retain evicts errors from the batch according to the retention policy.
+func (eb *ErrBatch) retain() {
+	opts := eb.getOptions()
+	n := opts.maxErrors
+	if n <= 0 || len(eb.entries) <= n {
+		return
+	}
+	eb.drop(len(eb.entries) - n)
+	// Reuse the underlying storage instead of reslicing,
+	// so it doesn't keep growing.
+	copy(eb.entries, eb.entries[len(eb.entries)-n:])
+	clear(eb.entries[n:])
+	eb.entries = eb.entries[:n]
+}
@@ -0,0 +1,195 @@
+package errbatch_test
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/fishy/errbatch"
+)
+
+func TestWithTopN(t *testing.T) {
+	rank := func(err error) int {
+		switch {
+		case strings.HasPrefix(err.Error(), "fatal"):
+			return 2
+		case strings.HasPrefix(err.Error(), "error"):
+			return 1
+		default:
+			return 0
+		}
+	}
+
+	batch := errbatch.New(errbatch.WithTopN(3, rank))
+	for _, msg := range []string{
+		"warn 1",
+		"error 1",
+		"warn 2",
+		"fatal 1",
+		"error 2",
+		"warn 3",
+		"error 3",
+	} {
+		batch.Add(errors.New(msg))
+	}
+	expected := []string{"error 1", "fatal 1", "error 2"}
+	if actual := messages(batch.GetErrors()); !slices.Equal(actual, expected) {
+		t.Errorf("Expected %q, got %q", expected, actual)
+	}
+	if actual := batch.Dropped(); actual != 4 {
+		t.Errorf("Expected 4 dropped, got %d", actual)
+	}
+
+	t.Run("batch", func(t *testing.T) {
+		var other errbatch.ErrBatch
+		other.Add(errors.New("fatal 2"))
+		other.Add(errors.New("fatal 3"))
+		batch.Add(&other)
+		expected := []string{"fatal 1", "fatal 2", "fatal 3"}
+		if actual := messages(batch.GetErrors()); !slices.Equal(actual, expected) {
+			t.Errorf("Expected %q, got %q", expected, actual)
+		}
+		if actual := batch.Dropped(); actual != 6 {
+			t.Errorf("Expected 6 dropped, got %d", actual)
+		}
+	})
+
+	t.Run("clear", func(t *testing.T) {
+		batch.Clear()
+		if actual := batch.Dropped(); actual != 0 {
+			t.Errorf("Expected 0 dropped after Clear, got %d", actual)
+		}
+	})
+
+	t.Run("rank-calls", func(t *testing.T) {
+		var calls int
+		batch := errbatch.New(errbatch.WithTopN(2, func(err error) int {
+			calls++
+			return rank(err)
+		}))
+		for range 100 {
+			batch.Add(errors.New("warn"))
+		}
+		if calls != 100 {
+			t.Errorf("Expected rank to be called 100 times, got %d", calls)
+		}
+		if actual := batch.Dropped(); actual != 98 {
+			t.Errorf("Expected 98 dropped, got %d", actual)
+		}
+	})
+
+	t.Run("concurrent", func(t *testing.T) {
+		cb := errbatch.NewConcurrent(errbatch.WithTopN(1, rank))
+		cb.Add(errors.New("warn"))
+		cb.Add(errors.New("fatal"))
+		snapshot := cb.Snapshot()
+		expected := []string{"fatal"}
+		if actual := messages(snapshot.GetErrors()); !slices.Equal(actual, expected) {
+			t.Errorf("Expected %q, got %q", expected, actual)
+		}
+		if actual := snapshot.Dropped(); actual != 1 {
+			t.Errorf("Expected 1 dropped, got %d", actual)
+		}
+	})
+}
+
+func TestWithRingBuffer(t *testing.T) {
+	batch := errbatch.New(errbatch.WithRingBuffer(2))
+	for _, msg := range []string{"foo", "bar", "foobar"} {
+		batch.Add(errors.New(msg))
+	}
+	expected := []string{"bar", "foobar"}
+	if actual := messages(batch.GetErrors()); !slices.Equal(actual, expected) {
+		t.Errorf("Expected %q, got %q", expected, actual)
+	}
+	if actual := batch.Dropped(); actual != 1 {
+		t.Errorf("Expected 1 dropped, got %d", actual)
+	}
+
+	t.Run("batch", func(t *testing.T) {
+		var other errbatch.ErrBatch
+		for _, msg := range []string{"a", "b", "c"} {
+			other.Add(errors.New(msg))
+		}
+		batch.Add(&other)
+		expected := []string{"b", "c"}
+		if actual := messages(batch.GetErrors()); !slices.Equal(actual, expected) {
+			t.Errorf("Expected %q, got %q", expected, actual)
+		}
+		if actual := batch.Dropped(); actual != 4 {
+			t.Errorf("Expected 4 dropped, got %d", actual)
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		batch := errbatch.New(errbatch.WithRingBuffer(0))
+		for _, msg := range []string{"foo", "bar", "foobar"} {
+			batch.Add(errors.New(msg))
+		}
+		if actual := len(batch.GetErrors()); actual != 3 {
+			t.Errorf("Expected 3 errors, got %d", actual)
+		}
+	})
+}
+
+func TestWithUniqueLRU(t *testing.T) {
+	batch := errbatch.New(errbatch.WithUniqueLRU(2, nil))
+	for _, msg := range []string{"foo", "bar", "foo", "foobar", "foo"} {
+		batch.Add(errors.New(msg))
+	}
+	expected := []string{"foobar", "foo"}
+	if actual := messages(batch.GetErrors()); !slices.Equal(actual, expected) {
+		t.Errorf("Expected %q, got %q", expected, actual)
+	}
+	if actual, expected := batch.Counts(), []int{1, 3}; !slices.Equal(actual, expected) {
+		t.Errorf("Expected counts %v, got %v", expected, actual)
+	}
+	if actual := batch.Dropped(); actual != 1 {
+		t.Errorf("Expected 1 dropped, got %d", actual)
+	}
+
+	t.Run("fingerprint", func(t *testing.T) {
+		batch := errbatch.New(errbatch.WithUniqueLRU(0, func(err error) string {
+			return strings.Fields(err.Error())[0]
+		}))
+		for _, msg := range []string{"timeout a", "refused b", "timeout c"} {
+			batch.Add(errors.New(msg))
+		}
+		expected := []string{"refused b", "timeout a"}
+		if actual := messages(batch.GetErrors()); !slices.Equal(actual, expected) {
+			t.Errorf("Expected %q, got %q", expected, actual)
+		}
+		if actual, expected := batch.Counts(), []int{1, 2}; !slices.Equal(actual, expected) {
+			t.Errorf("Expected counts %v, got %v", expected, actual)
+		}
+	})
+
+	t.Run("merge", func(t *testing.T) {
+		other := errbatch.New(errbatch.WithUniqueLRU(0, nil))
+		other.Add(errors.New("foobar"))
+		other.Add(errors.New("foobar"))
+		batch.Add(other)
+		expected := []string{"foo", "foobar"}
+		if actual := messages(batch.GetErrors()); !slices.Equal(actual, expected) {
+			t.Errorf("Expected %q, got %q", expected, actual)
+		}
+		if actual, expected := batch.Counts(), []int{3, 3}; !slices.Equal(actual, expected) {
+			t.Errorf("Expected counts %v, got %v", expected, actual)
+		}
+	})
+
+	t.Run("clear", func(t *testing.T) {
+		batch.Clear()
+		batch.Add(errors.New("foo"))
+		batch.Add(errors.New("bar"))
+		batch.Add(errors.New("foo"))
+		expected := []string{"bar", "foo"}
+		if actual := messages(batch.GetErrors()); !slices.Equal(actual, expected) {
+			t.Errorf("Expected %q, got %q", expected, actual)
+		}
+		if actual, expected := batch.Counts(), []int{1, 2}; !slices.Equal(actual, expected) {
+			t.Errorf("Expected counts %v, got %v", expected, actual)
+		}
+	})
+}
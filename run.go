@@ -0,0 +1,98 @@
+package errbatch
+
+import (
+	"slices"
+	"sync"
+)
+
+// RunAll runs fns sequentially and returns the compiled batch of the errors
+// they returned.
+//
+// Unlike a chain of "if err := fn(); err != nil { return err }",
+// it keeps running the remaining functions after a failure,
+// which is useful for things like migrations and maintenance scripts where
+// every step should be attempted and all failures reported.
+//
+// Nil functions are skipped.
+func RunAll(fns ...func() error) error {
+	var batch ErrBatch
+	for _, fn := range fns {
+		if fn != nil {
+			batch.Add(fn())
+		}
+	}
+	return batch.Compile()
+}
+
+// MultiFunc composes fns into a single function,
+// which runs all of them the same as RunAll every time it's called,
+// like an error-returning analogue of io.MultiWriter.
+//
+// It's useful to pass multiple cleanups into APIs taking a single
+// func() error.
+func MultiFunc(fns ...func() error) func() error {
+	fns = slices.Clone(fns)
+	return func() error {
+		return RunAll(fns...)
+	}
+}
+
+// Hooks runs named callbacks in their registration order,
+// for startup and teardown hook systems.
+//
+// Like RunAll, a failed hook does not stop the subsequent ones.
+// The errors are labeled with the names of the hooks returning them (as if
+// added via ErrBatch.AddNamed).
+//
+// The zero value of Hooks is valid and ready to use.
+// A Hooks must not be copied after first use.
+type Hooks struct {
+	mu    sync.Mutex
+	hooks []namedHook
+	opts  []Option
+}
+
+type namedHook struct {
+	name string
+	fn   func() error
+}
+
+// NewHooks creates a new Hooks with the given options,
+// which are used by the batch returned by Run.
+func NewHooks(opts ...Option) *Hooks {
+	return &Hooks{
+		opts: opts,
+	}
+}
+
+// Register registers fn as a hook with name.
+//
+// It's safe to call Register concurrently, including with Run,
+// but hooks registered during a Run are not run by it.
+//
+// Nil fn is skipped, the same as RunAll.
+func (h *Hooks) Register(name string, fn func() error) {
+	if fn == nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.hooks = append(h.hooks, namedHook{name: name, fn: fn})
+}
+
+// Run runs all the registered hooks in their registration order,
+// and returns the compiled batch of their errors,
+// e.g. "errbatch: total 2 error(s) in this batch: migrate: timeout; warm-cache: EOF".
+//
+// See ErrBatch.Compile for details of the returned error.
+func (h *Hooks) Run() error {
+	h.mu.Lock()
+	hooks := slices.Clone(h.hooks)
+	h.mu.Unlock()
+
+	batch := New(h.opts...)
+	for _, hook := range hooks {
+		batch.AddNamed(hook.name, hook.fn())
+	}
+	return batch.Compile()
+}
@@ -0,0 +1,115 @@
+package errbatch_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/fishy/errbatch"
+)
+
+func TestRunAll(t *testing.T) {
+	if err := errbatch.RunAll(); err != nil {
+		t.Errorf("Expected nil, got %#v", err)
+	}
+
+	err0 := errors.New("foo")
+	err1 := errors.New("bar")
+	var order []int
+	err := errbatch.RunAll(
+		func() error {
+			order = append(order, 0)
+			return err0
+		},
+		nil,
+		func() error {
+			order = append(order, 1)
+			return nil
+		},
+		func() error {
+			order = append(order, 2)
+			return err1
+		},
+	)
+	if expected := []int{0, 1, 2}; !reflect.DeepEqual(order, expected) {
+		t.Errorf("Expected functions to run in order %v, got %v", expected, order)
+	}
+	var batch *errbatch.ErrBatch
+	if !errors.As(err, &batch) {
+		t.Fatalf("Expected a batch, got %#v", err)
+	}
+	if expected := []error{err0, err1}; !reflect.DeepEqual(batch.GetErrors(), expected) {
+		t.Errorf("Expected %#v, got %#v", expected, batch.GetErrors())
+	}
+
+	if err := errbatch.RunAll(func() error { return err0 }); err != err0 {
+		t.Errorf("Expected %#v, got %#v", err0, err)
+	}
+}
+
+func TestMultiFunc(t *testing.T) {
+	err0 := errors.New("foo")
+	var calls int
+	fns := []func() error{
+		func() error {
+			calls++
+			return err0
+		},
+		func() error {
+			calls++
+			return nil
+		},
+	}
+	fn := errbatch.MultiFunc(fns...)
+	// Modifying the slice after composing does not affect fn.
+	fns[1] = nil
+
+	for i := range 2 {
+		if err := fn(); err != err0 {
+			t.Errorf("%d: Expected %#v, got %#v", i, err0, err)
+		}
+	}
+	if calls != 4 {
+		t.Errorf("Expected 4 calls, got %d", calls)
+	}
+
+	if err := errbatch.MultiFunc()(); err != nil {
+		t.Errorf("Expected nil, got %#v", err)
+	}
+}
+
+func TestHooks(t *testing.T) {
+	var hooks errbatch.Hooks
+	if err := hooks.Run(); err != nil {
+		t.Errorf("Expected nil, got %#v", err)
+	}
+
+	var order []string
+	hook := func(name string, err error) func() error {
+		return func() error {
+			order = append(order, name)
+			return err
+		}
+	}
+	hooks.Register("a", hook("a", errors.New("foo")))
+	hooks.Register("b", hook("b", nil))
+	hooks.Register("nil", nil)
+	hooks.Register("c", hook("c", errors.New("bar")))
+
+	const expected = "errbatch: total 2 error(s) in this batch: a: foo; c: bar"
+	if err := hooks.Run(); err == nil || err.Error() != expected {
+		t.Errorf("Expected %q, got %v", expected, err)
+	}
+	if expected := []string{"a", "b", "c"}; !reflect.DeepEqual(order, expected) {
+		t.Errorf("Expected %q, got %q", expected, order)
+	}
+
+	var batch *errbatch.ErrBatch
+	if err := hooks.Run(); errors.As(err, &batch) {
+		if keys := batch.ErrorsByKey(); len(keys["a"]) != 1 || len(keys["c"]) != 1 {
+			t.Errorf("Expected errors keyed by hook names, got %#v", keys)
+		}
+	} else {
+		t.Errorf("Expected a batch, got %#v", err)
+	}
+}
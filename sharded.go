@@ -0,0 +1,113 @@
+package errbatch
+
+import (
+	"math/rand/v2"
+	"runtime"
+	"sync"
+)
+
+// ShardedErrBatch is a thread-safe batch optimized for high contention.
+//
+// Errors are added into one of several shards picked randomly,
+// each guarded by its own mutex,
+// so that thousands of goroutines adding errors at the same time do not
+// contend on a single mutex.
+// Compile merges all the shards,
+// ordered by the sequence the errors were added (see WithSequenceOrder).
+//
+// The zero value of ShardedErrBatch is valid (with no errors) and ready to
+// use, with runtime.GOMAXPROCS(0) shards.
+// ShardedErrBatch must not be copied after first use.
+type ShardedErrBatch struct {
+	initOnce sync.Once
+	n        int
+	shards   []shard
+}
+
+type shard struct {
+	mu    sync.Mutex
+	batch ErrBatch
+
+	// Avoid false sharing between shards.
+	_ [64]byte
+}
+
+// NewSharded creates a new ShardedErrBatch with n shards.
+//
+// If n <= 0, runtime.GOMAXPROCS(0) will be used.
+func NewSharded(n int) *ShardedErrBatch {
+	return &ShardedErrBatch{
+		n: n,
+	}
+}
+
+func (sb *ShardedErrBatch) init() {
+	sb.initOnce.Do(func() {
+		n := sb.n
+		if n <= 0 {
+			n = runtime.GOMAXPROCS(0)
+		}
+		sb.shards = make([]shard, n)
+		opts := New(WithSequenceOrder()).opts
+		for i := range sb.shards {
+			sb.shards[i].batch.opts = opts
+		}
+	})
+}
+
+// Add adds an error into one of the shards.
+//
+// See ErrBatch.Add for details.
+func (sb *ShardedErrBatch) Add(err error) {
+	if err == nil {
+		return
+	}
+	sb.init()
+
+	s := &sb.shards[rand.IntN(len(sb.shards))]
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.batch.Add(err)
+}
+
+// merge merges all the shards into a single batch.
+func (sb *ShardedErrBatch) merge() *ErrBatch {
+	sb.init()
+
+	var entries []entry
+	for i := range sb.shards {
+		s := &sb.shards[i]
+		s.mu.Lock()
+		entries = append(entries, s.batch.entries...)
+		s.mu.Unlock()
+	}
+	merged := New(WithSequenceOrder())
+	merged.appendEntries(entries...)
+	merged.sortBySeq()
+	return merged
+}
+
+// Compile merges all the shards and compiles the merged batch.
+//
+// See ErrBatch.Compile for details.
+func (sb *ShardedErrBatch) Compile() error {
+	return sb.merge().Compile()
+}
+
+// GetErrors returns a copy of the underlying error(s) from all the shards,
+// ordered by the sequence they were added.
+func (sb *ShardedErrBatch) GetErrors() []error {
+	return sb.merge().GetErrors()
+}
+
+// Clear clears all the shards.
+func (sb *ShardedErrBatch) Clear() {
+	sb.init()
+
+	for i := range sb.shards {
+		s := &sb.shards[i]
+		s.mu.Lock()
+		s.batch.Clear()
+		s.mu.Unlock()
+	}
+}
@@ -0,0 +1,76 @@
+package errbatch_test
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/fishy/errbatch"
+)
+
+func TestShardedErrBatch(t *testing.T) {
+	var batch errbatch.ShardedErrBatch
+	if err := batch.Compile(); err != nil {
+		t.Errorf("An empty batch should be compiled to nil, got: %#v", err)
+	}
+
+	expected := make([]error, 10)
+	for i := range expected {
+		expected[i] = fmt.Errorf("error %d", i)
+		batch.Add(expected[i])
+		batch.Add(nil)
+	}
+	if errs := batch.GetErrors(); !reflect.DeepEqual(errs, expected) {
+		t.Errorf("Expected %v, got %v", expected, errs)
+	}
+
+	batch.Clear()
+	err0 := errors.New("foo")
+	batch.Add(err0)
+	if err := batch.Compile(); err != err0 {
+		t.Errorf("A single error batch should be compiled to %#v, got %#v", err0, err)
+	}
+}
+
+func TestShardedErrBatchConcurrent(t *testing.T) {
+	const n = 100
+	batch := errbatch.NewSharded(4)
+	var wg sync.WaitGroup
+	wg.Add(n)
+	for i := 0; i < n; i++ {
+		go func(i int) {
+			defer wg.Done()
+			batch.Add(fmt.Errorf("error %d", i))
+		}(i)
+	}
+	wg.Wait()
+
+	if errs := batch.GetErrors(); len(errs) != n {
+		t.Errorf("Expected %d errors, got %d", n, len(errs))
+	}
+}
+
+func BenchmarkShardedErrBatch(b *testing.B) {
+	err := errors.New("foo")
+	b.Run("sharded", func(b *testing.B) {
+		var batch errbatch.ShardedErrBatch
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				batch.Add(err)
+			}
+		})
+	})
+	b.Run("mutex", func(b *testing.B) {
+		var mu sync.Mutex
+		var batch errbatch.ErrBatch
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				mu.Lock()
+				batch.Add(err)
+				mu.Unlock()
+			}
+		})
+	})
+}
@@ -0,0 +1,164 @@
+package errbatch
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Shutdowner calls registered shutdown hooks and aggregates their errors,
+// for the classic shutdown sequence at the end of main().
+//
+// The zero value of Shutdowner is valid and ready to use,
+// calling the hooks concurrently without an additional timeout.
+// A Shutdowner must not be copied after first use.
+//
+// Example:
+//
+//	var shutdowner errbatch.Shutdowner
+//	shutdowner.Timeout = 10 * time.Second
+//	shutdowner.Register("http", server.Shutdown)
+//	shutdowner.Register("db", func(context.Context) error {
+//		return db.Close()
+//	})
+//	// ...
+//	if err := shutdowner.Shutdown(context.Background()); err != nil {
+//		log.Printf("Failed to shut down: %v", err)
+//	}
+type Shutdowner struct {
+	// Serial makes Shutdown call the hooks one by one,
+	// in the reverse order of their registration (like defer),
+	// instead of concurrently.
+	Serial bool
+
+	// Timeout is the overall deadline of Shutdown,
+	// applied on top of the context passed in.
+	// 0 means no additional deadline.
+	Timeout time.Duration
+
+	mu    sync.Mutex
+	hooks []shutdownHook
+	opts  []Option
+}
+
+type shutdownHook struct {
+	name string
+	fn   func(context.Context) error
+}
+
+// NewShutdowner creates a new Shutdowner with the given options,
+// which are used by the batch returned by Shutdown.
+func NewShutdowner(opts ...Option) *Shutdowner {
+	return &Shutdowner{
+		opts: opts,
+	}
+}
+
+// Register registers a shutdown hook,
+// its error is labeled with name (as if added via ErrBatch.AddNamed).
+//
+// It's safe to call Register concurrently with other Register calls,
+// but not with Shutdown.
+func (s *Shutdowner) Register(name string, fn func(context.Context) error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.hooks = append(s.hooks, shutdownHook{name: name, fn: fn})
+}
+
+// Shutdown calls all the registered hooks with a context derived from ctx
+// (with Timeout applied), and returns the compiled batch of their errors.
+//
+// It returns when all the hooks return, or when the context is done,
+// whichever comes first,
+// so a hook ignoring the context can't block it beyond the deadline.
+// Each hook not returned (or, with Serial, not called) by then is reported
+// with an error wrapping the cause of the context.
+//
+// The errors are in the order of the hooks' registration,
+// or the order they are called with Serial.
+// See ErrBatch.Compile for details of the returned error.
+func (s *Shutdowner) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	hooks := slices.Clone(s.hooks)
+	s.mu.Unlock()
+
+	if s.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.Timeout)
+		defer cancel()
+	}
+
+	batch := New(s.opts...)
+	if s.Serial {
+		slices.Reverse(hooks)
+		for _, hook := range hooks {
+			var result <-chan error
+			if ctx.Err() == nil {
+				result = hook.start(ctx)
+			}
+			batch.AddNamed(hook.name, awaitHook(ctx, result))
+		}
+	} else {
+		results := make([]<-chan error, len(hooks))
+		for i, hook := range hooks {
+			results[i] = hook.start(ctx)
+		}
+		for i, hook := range hooks {
+			batch.AddNamed(hook.name, awaitHook(ctx, results[i]))
+		}
+	}
+	return batch.Compile()
+}
+
+// start calls the hook in a new goroutine,
+// the returned channel receives its error.
+func (h shutdownHook) start(ctx context.Context) <-chan error {
+	result := make(chan error, 1)
+	go func() {
+		result <- h.fn(ctx)
+	}()
+	return result
+}
+
+// awaitHook waits for the error of a hook from result,
+// or returns an error when ctx is done first.
+//
+// Nil result means the hook was never called.
+func awaitHook(ctx context.Context, result <-chan error) error {
+	// Prefer the result if it's already available.
+	select {
+	case err := <-result:
+		return err
+	default:
+	}
+	select {
+	case err := <-result:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("shutdown not finished: %w", context.Cause(ctx))
+	}
+}
+
+// ShutdownAll shuts down all of servers (e.g. *http.Server) in parallel with
+// ctx, and returns the compiled batch of their errors.
+//
+// Unlike Shutdowner, it waits for all the Shutdown calls to return,
+// which is usually bounded by ctx (e.g. for *http.Server).
+// The errors are in the order they were returned,
+// each labeled with the index and type of its server
+// (as if added via ErrBatch.AddNamed),
+// e.g. "errbatch: total 2 error(s) in this batch: 0 (*http.Server): context deadline exceeded; 2 (*grpc.Server): EOF".
+// See ErrBatch.Compile for details of the returned error.
+func ShutdownAll(ctx context.Context, servers ...interface {
+	Shutdown(context.Context) error
+}) error {
+	var group Group
+	for i, server := range servers {
+		group.GoNamed(fmt.Sprintf("%d (%T)", i, server), func() error {
+			return server.Shutdown(ctx)
+		})
+	}
+	return group.Wait()
+}
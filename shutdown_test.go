@@ -0,0 +1,134 @@
+package errbatch_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fishy/errbatch"
+)
+
+func TestShutdowner(t *testing.T) {
+	errFoo := errors.New("foo")
+
+	t.Run("concurrent", func(t *testing.T) {
+		var s errbatch.Shutdowner
+		s.Register("a", func(context.Context) error {
+			return errFoo
+		})
+		s.Register("b", func(context.Context) error {
+			return nil
+		})
+		s.Register("c", func(context.Context) error {
+			return errFoo
+		})
+		const expected = "errbatch: total 2 error(s) in this batch: a: foo; c: foo"
+		if err := s.Shutdown(context.Background()); err == nil || err.Error() != expected {
+			t.Errorf("Expected %q, got %v", expected, err)
+		}
+	})
+
+	t.Run("serial", func(t *testing.T) {
+		var mu sync.Mutex
+		var order []string
+		hook := func(name string) func(context.Context) error {
+			return func(context.Context) error {
+				mu.Lock()
+				defer mu.Unlock()
+				order = append(order, name)
+				return nil
+			}
+		}
+		s := errbatch.Shutdowner{Serial: true}
+		s.Register("a", hook("a"))
+		s.Register("b", hook("b"))
+		s.Register("c", hook("c"))
+		if err := s.Shutdown(context.Background()); err != nil {
+			t.Errorf("Expected nil, got %#v", err)
+		}
+		if expected := []string{"c", "b", "a"}; !slices.Equal(order, expected) {
+			t.Errorf("Expected %q, got %q", expected, order)
+		}
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		block := make(chan struct{})
+		defer close(block)
+		for _, c := range []struct {
+			serial   bool
+			expected string
+		}{
+			{
+				expected: "stuck: shutdown not finished: context deadline exceeded",
+			},
+			{
+				// "stuck" never returns, so "a" is never called.
+				serial: true,
+				expected: "errbatch: total 2 error(s) in this batch: " +
+					"stuck: shutdown not finished: context deadline exceeded; " +
+					"a: shutdown not finished: context deadline exceeded",
+			},
+		} {
+			s := errbatch.NewShutdowner()
+			s.Serial = c.serial
+			s.Timeout = 10 * time.Millisecond
+			s.Register("a", func(context.Context) error {
+				return nil
+			})
+			s.Register("stuck", func(context.Context) error {
+				// Ignores the context.
+				<-block
+				return nil
+			})
+			s.Register("c", func(context.Context) error {
+				return nil
+			})
+			if err := s.Shutdown(context.Background()); err == nil || err.Error() != c.expected {
+				t.Errorf("serial=%v: Expected %q, got %v", c.serial, c.expected, err)
+			}
+		}
+	})
+}
+
+type fakeServer struct {
+	err  error
+	down bool
+}
+
+func (s *fakeServer) Shutdown(context.Context) error {
+	s.down = true
+	return s.err
+}
+
+func TestShutdownAll(t *testing.T) {
+	errFoo := errors.New("foo")
+	servers := []*fakeServer{
+		{},
+		{err: errFoo},
+		{},
+	}
+	err := errbatch.ShutdownAll(context.Background(), servers[0], servers[1], servers[2])
+	if !errors.Is(err, errFoo) {
+		t.Errorf("Expected %#v, got %#v", errFoo, err)
+	}
+	const expected = "1 (*errbatch_test.fakeServer): foo"
+	if actual := err.Error(); actual != expected {
+		t.Errorf("Expected %q, got %q", expected, actual)
+	}
+	for i, s := range servers {
+		if !s.down {
+			t.Errorf("Expected server %d to be shut down", i)
+		}
+	}
+
+	t.Run("http", func(t *testing.T) {
+		var server http.Server
+		if err := errbatch.ShutdownAll(context.Background(), &server); err != nil {
+			t.Errorf("Expected nil, got %#v", err)
+		}
+	})
+}
@@ -0,0 +1,32 @@
+package errbatch
+
+import (
+	"errors"
+
+	pkgerrors "github.com/pkg/errors"
+)
+
+// stackTracer is the interface implemented by errors created by
+// github.com/pkg/errors.
+type stackTracer interface {
+	StackTrace() pkgerrors.StackTrace
+}
+
+// StackTraces returns the github.com/pkg/errors stack traces carried by the
+// underlying error(s).
+//
+// The returned slice has the same length and order as GetErrors.
+// For errors without stack traces, the corresponding element is nil.
+//
+// Note that the stack traces are also rendered by the verbose (%+v) output of
+// the batch.
+func (eb *ErrBatch) StackTraces() []pkgerrors.StackTrace {
+	traces := make([]pkgerrors.StackTrace, len(eb.entries))
+	for i, e := range eb.entries {
+		var st stackTracer
+		if errors.As(e.err, &st) {
+			traces[i] = st.StackTrace()
+		}
+	}
+	return traces
+}
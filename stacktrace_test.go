@@ -0,0 +1,34 @@
+package errbatch_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	pkgerrors "github.com/pkg/errors"
+
+	"github.com/fishy/errbatch"
+)
+
+func TestStackTraces(t *testing.T) {
+	var batch errbatch.ErrBatch
+	batch.Add(pkgerrors.New("foo"))
+	batch.Add(errors.New("bar"))
+	batch.Add(fmt.Errorf("wrapped: %w", pkgerrors.New("foobar")))
+
+	traces := batch.StackTraces()
+	if len(traces) != 3 {
+		t.Fatalf("Expected 3 stack traces, got %d", len(traces))
+	}
+	for i, expected := range []bool{true, false, true} {
+		if actual := traces[i] != nil; actual != expected {
+			t.Errorf("traces[%d] != nil expected %v, got %v", i, expected, actual)
+		}
+	}
+
+	verbose := fmt.Sprintf("%+v", &batch)
+	if !strings.Contains(verbose, "stacktrace_test.go:") {
+		t.Errorf("Expected stack trace in verbose output, got %q", verbose)
+	}
+}
@@ -0,0 +1,111 @@
+package errbatch
+
+import (
+	"sync/atomic"
+)
+
+// Metric names reported to StatsSink.
+const (
+	// MetricAdded is counted with the number of errors added into a batch.
+	MetricAdded = "errbatch.added"
+
+	// MetricCompiled is counted with 1 every time Compile is called.
+	MetricCompiled = "errbatch.compiled"
+
+	// MetricDropped is counted with the number of errors not kept by a batch
+	// because of WithIgnore, WithDedup or a retention policy (e.g.
+	// WithRingBuffer).
+	MetricDropped = "errbatch.dropped"
+)
+
+var (
+	statsAdded    atomic.Int64
+	statsCompiled atomic.Int64
+	statsDropped  atomic.Int64
+
+	statsSink atomic.Pointer[statsSinkHolder]
+)
+
+// StatsSink is the minimal interface a metrics client needs to implement to
+// receive package-level counters.
+//
+// It's compatible with most statsd clients, and can be easily adapted to
+// Prometheus counters.
+//
+// Count must be safe to be called concurrently.
+type StatsSink interface {
+	Count(name string, n int, tags ...string)
+}
+
+// wrapper to make sure atomic operations always use the same concrete type.
+type statsSinkHolder struct {
+	sink StatsSink
+}
+
+// SetStatsSink sets the StatsSink to report package-level counters to.
+//
+// The default sink does nothing.
+// Passing nil sink restores the default.
+func SetStatsSink(sink StatsSink) {
+	if sink == nil {
+		statsSink.Store(nil)
+		return
+	}
+	statsSink.Store(&statsSinkHolder{sink: sink})
+}
+
+func count(name string, n int) {
+	if holder := statsSink.Load(); holder != nil {
+		holder.sink.Count(name, n)
+	}
+}
+
+func countAdded(n int) {
+	if n == 0 {
+		return
+	}
+	statsAdded.Add(int64(n))
+	count(MetricAdded, n)
+}
+
+func countDropped(n int) {
+	if n == 0 {
+		return
+	}
+	statsDropped.Add(int64(n))
+	count(MetricDropped, n)
+}
+
+func countCompiled() {
+	statsCompiled.Add(1)
+	count(MetricCompiled, 1)
+}
+
+// Stats are the package-level counters accumulated across all batches in the
+// process.
+type Stats struct {
+	// Total number of errors added into batches.
+	//
+	// Nil errors are not counted.
+	// When a batch is added into another batch,
+	// its underlying errors are counted again.
+	Added int64
+
+	// Total number of times Compile was called.
+	Compiled int64
+
+	// Total number of errors not kept by batches because of WithIgnore,
+	// WithDedup or a retention policy (e.g. WithRingBuffer).
+	//
+	// Errors evicted by a retention policy were already counted in Added.
+	Dropped int64
+}
+
+// GetStats returns a snapshot of the current package-level counters.
+func GetStats() Stats {
+	return Stats{
+		Added:    statsAdded.Load(),
+		Compiled: statsCompiled.Load(),
+		Dropped:  statsDropped.Load(),
+	}
+}
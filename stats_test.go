@@ -0,0 +1,95 @@
+package errbatch_test
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/fishy/errbatch"
+)
+
+func TestStats(t *testing.T) {
+	before := errbatch.GetStats()
+
+	var batch errbatch.ErrBatch
+	batch.Add(nil)
+	batch.Add(errors.New("foo"))
+	var another errbatch.ErrBatch
+	another.Add(errors.New("bar"))
+	another.Add(errors.New("foobar"))
+	batch.Add(&another)
+	batch.Compile()
+
+	after := errbatch.GetStats()
+	if diff := after.Added - before.Added; diff != 5 {
+		t.Errorf("Expected Added to increase by 5, got %d", diff)
+	}
+	if diff := after.Compiled - before.Compiled; diff != 1 {
+		t.Errorf("Expected Compiled to increase by 1, got %d", diff)
+	}
+}
+
+func TestStatsDropped(t *testing.T) {
+	before := errbatch.GetStats()
+
+	batch := errbatch.New(
+		errbatch.WithIgnore(io.EOF),
+		errbatch.WithDedup(errbatch.DedupByMessage),
+		errbatch.WithRingBuffer(2),
+	)
+	batch.Add(io.EOF)
+	batch.Add(errbatch.FromSlice([]error{io.EOF, errors.New("foo")}))
+	batch.Add(errors.New("foo"))
+	batch.Add(errors.New("bar"))
+	batch.Add(errors.New("foobar"))
+
+	after := errbatch.GetStats()
+	// 2 ignored, 1 duplicate, and 1 evicted.
+	if diff := after.Dropped - before.Dropped; diff != 4 {
+		t.Errorf("Expected Dropped to increase by 4, got %d", diff)
+	}
+}
+
+type recordingSink map[string]int
+
+func (s recordingSink) Count(name string, n int, tags ...string) {
+	s[name] += n
+}
+
+func TestStatsSink(t *testing.T) {
+	sink := make(recordingSink)
+	errbatch.SetStatsSink(sink)
+	defer errbatch.SetStatsSink(nil)
+
+	var batch errbatch.ErrBatch
+	batch.Add(nil)
+	batch.Add(errors.New("foo"))
+	batch.Add(errors.New("bar"))
+	batch.Compile()
+	batch.Compile()
+
+	if sink[errbatch.MetricAdded] != 2 {
+		t.Errorf(
+			"Expected %q to be 2, got %d",
+			errbatch.MetricAdded,
+			sink[errbatch.MetricAdded],
+		)
+	}
+	if sink[errbatch.MetricCompiled] != 2 {
+		t.Errorf(
+			"Expected %q to be 2, got %d",
+			errbatch.MetricCompiled,
+			sink[errbatch.MetricCompiled],
+		)
+	}
+
+	errbatch.SetStatsSink(nil)
+	batch.Add(errors.New("foobar"))
+	if sink[errbatch.MetricAdded] != 2 {
+		t.Errorf(
+			"Expected %q to be unchanged after reset, got %d",
+			errbatch.MetricAdded,
+			sink[errbatch.MetricAdded],
+		)
+	}
+}
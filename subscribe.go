@@ -0,0 +1,95 @@
+package errbatch
+
+import (
+	"slices"
+	"sync"
+)
+
+// SubscriptionBuffer is the buffer size of the channels returned by
+// ConcurrentErrBatch.Subscribe.
+const SubscriptionBuffer = 64
+
+type subscriber struct {
+	mu     sync.Mutex
+	ch     chan error
+	closed bool
+}
+
+// send sends err to the subscriber without blocking,
+// err is dropped if the buffer is full.
+func (s *subscriber) send(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	select {
+	case s.ch <- err:
+	default:
+	}
+}
+
+func (s *subscriber) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.closed {
+		s.closed = true
+		close(s.ch)
+	}
+}
+
+// Subscribe returns a channel receiving the errors added into the batch after
+// the call,
+// so a monitoring goroutine can observe failures in real time (e.g. for
+// progress bars or early aborts),
+// while the batch keeps aggregating them for the final compile.
+//
+// The errors are received the same as they are stored in the batch,
+// e.g. a batch added is received as its underlying errors.
+// The channel has a buffer of SubscriptionBuffer errors,
+// and errors are dropped for the subscriber when its buffer is full,
+// so a slow subscriber never blocks Add.
+//
+// The returned function unsubscribes and closes the channel,
+// it's safe to be called multiple times.
+func (cb *ConcurrentErrBatch) Subscribe() (<-chan error, func()) {
+	s := &subscriber{
+		ch: make(chan error, SubscriptionBuffer),
+	}
+	cb.updateSubs(func(subs []*subscriber) []*subscriber {
+		return append(subs, s)
+	})
+	return s.ch, func() {
+		cb.updateSubs(func(subs []*subscriber) []*subscriber {
+			return slices.DeleteFunc(subs, func(other *subscriber) bool {
+				return other == s
+			})
+		})
+		s.close()
+	}
+}
+
+// updateSubs updates the subscribers with a copy of the current ones.
+func (cb *ConcurrentErrBatch) updateSubs(update func([]*subscriber) []*subscriber) {
+	cb.subsMu.Lock()
+	defer cb.subsMu.Unlock()
+	var subs []*subscriber
+	if current := cb.subs.Load(); current != nil {
+		subs = slices.Clone(*current)
+	}
+	subs = update(subs)
+	cb.subs.Store(&subs)
+}
+
+// publish sends the errors of entries to the subscribers.
+func (cb *ConcurrentErrBatch) publish(entries []entry) {
+	subs := cb.subs.Load()
+	if subs == nil {
+		return
+	}
+	for _, s := range *subs {
+		for _, e := range entries {
+			s.send(e.err)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package errbatch_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/fishy/errbatch"
+)
+
+func TestSubscribe(t *testing.T) {
+	var batch errbatch.ConcurrentErrBatch
+	batch.Add(errors.New("before"))
+
+	ch1, unsubscribe1 := batch.Subscribe()
+	ch2, unsubscribe2 := batch.Subscribe()
+	defer unsubscribe2()
+
+	errFoo := errors.New("foo")
+	batch.Add(errFoo)
+	batch.Add(nil)
+	for i, ch := range []<-chan error{ch1, ch2} {
+		if err := <-ch; err != errFoo {
+			t.Errorf("%d: Expected %#v, got %#v", i, errFoo, err)
+		}
+	}
+
+	unsubscribe1()
+	unsubscribe1()
+	if _, ok := <-ch1; ok {
+		t.Error("Expected channel to be closed after unsubscribe")
+	}
+
+	var nested errbatch.ErrBatch
+	nested.Add(errors.New("bar"))
+	nested.Add(errors.New("foobar"))
+	batch.Add(&nested)
+	for _, expected := range []string{"bar", "foobar"} {
+		if err := <-ch2; err.Error() != expected {
+			t.Errorf("Expected %q, got %v", expected, err)
+		}
+	}
+
+	t.Run("full", func(t *testing.T) {
+		var batch errbatch.ConcurrentErrBatch
+		ch, unsubscribe := batch.Subscribe()
+		defer unsubscribe()
+		for range errbatch.SubscriptionBuffer + 1 {
+			batch.Add(errFoo)
+		}
+		if actual := len(ch); actual != errbatch.SubscriptionBuffer {
+			t.Errorf("Expected %d buffered, got %d", errbatch.SubscriptionBuffer, actual)
+		}
+		if actual := batch.Len(); actual != errbatch.SubscriptionBuffer+1 {
+			t.Errorf("Expected %d errors, got %d", errbatch.SubscriptionBuffer+1, actual)
+		}
+	})
+}
@@ -0,0 +1,192 @@
+// Package thriftbatch converts errbatch batches into Thrift exceptions.
+//
+// Exception is a hand-written equivalent of the code the Thrift compiler
+// generates for the following IDL:
+//
+//	exception ErrBatch {
+//	  1: string message,
+//	  2: list<string> errors,
+//	}
+//
+// So it can be declared in a service's IDL and thrown by a handler,
+// and clients in any language get every message of the batch.
+package thriftbatch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/apache/thrift/lib/go/thrift"
+
+	"github.com/fishy/errbatch"
+)
+
+// Field IDs of Exception.
+const (
+	fieldMessage = 1
+	fieldErrors  = 2
+)
+
+// Exception is a Thrift exception carrying the messages of a batch.
+type Exception struct {
+	// Message is the compiled message of the whole batch.
+	Message string
+	// Errors are the messages of each error in the batch.
+	Errors []string
+}
+
+var (
+	_ thrift.TException = (*Exception)(nil)
+	_ thrift.TStruct    = (*Exception)(nil)
+)
+
+// FromError converts err into an Exception.
+//
+// If err is an errbatch.ErrBatch
+// (or a batch recognized by errbatch.ErrBatch.Add),
+// Errors contains the message of each of its underlying errors.
+// Otherwise Errors contains err's own message only.
+//
+// It returns nil if err is nil.
+func FromError(err error) *Exception {
+	if err == nil {
+		return nil
+	}
+	var batch errbatch.ErrBatch
+	batch.Add(err)
+	errs := batch.GetErrors()
+	ex := &Exception{
+		Message: err.Error(),
+		Errors:  make([]string, 0, len(errs)),
+	}
+	for _, err := range errs {
+		ex.Errors = append(ex.Errors, err.Error())
+	}
+	return ex
+}
+
+// Error implements error.
+func (ex *Exception) Error() string {
+	return ex.Message
+}
+
+// TExceptionType implements thrift.TException.
+func (ex *Exception) TExceptionType() thrift.TExceptionType {
+	return thrift.TExceptionTypeCompiled
+}
+
+// Unwrap returns the errors as an errbatch.ErrBatch,
+// so that errbatch.ErrBatch.Add flattens an Exception received from the
+// wire into its individual messages.
+func (ex *Exception) Unwrap() error {
+	if len(ex.Errors) == 0 {
+		return nil
+	}
+	batch := new(errbatch.ErrBatch)
+	for _, msg := range ex.Errors {
+		batch.Add(errors.New(msg))
+	}
+	return batch
+}
+
+// Write implements thrift.TStruct.
+func (ex *Exception) Write(ctx context.Context, p thrift.TProtocol) error {
+	if err := p.WriteStructBegin(ctx, "ErrBatch"); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", ex), err)
+	}
+
+	if err := p.WriteFieldBegin(ctx, "message", thrift.STRING, fieldMessage); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error %d:message: ", ex, fieldMessage), err)
+	}
+	if err := p.WriteString(ctx, ex.Message); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.message (%d) field write error: ", ex, fieldMessage), err)
+	}
+	if err := p.WriteFieldEnd(ctx); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error %d:message: ", ex, fieldMessage), err)
+	}
+
+	if err := p.WriteFieldBegin(ctx, "errors", thrift.LIST, fieldErrors); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error %d:errors: ", ex, fieldErrors), err)
+	}
+	if err := p.WriteListBegin(ctx, thrift.STRING, len(ex.Errors)); err != nil {
+		return thrift.PrependError("error writing list begin: ", err)
+	}
+	for _, msg := range ex.Errors {
+		if err := p.WriteString(ctx, msg); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T. (0) field write error: ", ex), err)
+		}
+	}
+	if err := p.WriteListEnd(ctx); err != nil {
+		return thrift.PrependError("error writing list end: ", err)
+	}
+	if err := p.WriteFieldEnd(ctx); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error %d:errors: ", ex, fieldErrors), err)
+	}
+
+	if err := p.WriteFieldStop(ctx); err != nil {
+		return thrift.PrependError("write field stop error: ", err)
+	}
+	if err := p.WriteStructEnd(ctx); err != nil {
+		return thrift.PrependError("write struct stop error: ", err)
+	}
+	return nil
+}
+
+// Read implements thrift.TStruct.
+func (ex *Exception) Read(ctx context.Context, p thrift.TProtocol) error {
+	if _, err := p.ReadStructBegin(ctx); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read error: ", ex), err)
+	}
+
+	for {
+		_, fieldType, fieldID, err := p.ReadFieldBegin(ctx)
+		if err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T field %d read error: ", ex, fieldID), err)
+		}
+		if fieldType == thrift.STOP {
+			break
+		}
+		switch {
+		case fieldID == fieldMessage && fieldType == thrift.STRING:
+			if ex.Message, err = p.ReadString(ctx); err != nil {
+				return thrift.PrependError("error reading field 1: ", err)
+			}
+		case fieldID == fieldErrors && fieldType == thrift.LIST:
+			if err := ex.readErrors(ctx, p); err != nil {
+				return err
+			}
+		default:
+			if err := thrift.SkipDefaultDepth(ctx, p, fieldType); err != nil {
+				return err
+			}
+		}
+		if err := p.ReadFieldEnd(ctx); err != nil {
+			return err
+		}
+	}
+
+	if err := p.ReadStructEnd(ctx); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", ex), err)
+	}
+	return nil
+}
+
+func (ex *Exception) readErrors(ctx context.Context, p thrift.TProtocol) error {
+	_, size, err := p.ReadListBegin(ctx)
+	if err != nil {
+		return thrift.PrependError("error reading list begin: ", err)
+	}
+	ex.Errors = make([]string, 0, size)
+	for range size {
+		msg, err := p.ReadString(ctx)
+		if err != nil {
+			return thrift.PrependError("error reading field 0: ", err)
+		}
+		ex.Errors = append(ex.Errors, msg)
+	}
+	if err := p.ReadListEnd(ctx); err != nil {
+		return thrift.PrependError("error reading list end: ", err)
+	}
+	return nil
+}
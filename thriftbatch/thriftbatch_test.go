@@ -0,0 +1,97 @@
+package thriftbatch_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/apache/thrift/lib/go/thrift"
+
+	"github.com/fishy/errbatch"
+	"github.com/fishy/errbatch/thriftbatch"
+)
+
+func TestFromError(t *testing.T) {
+	if ex := thriftbatch.FromError(nil); ex != nil {
+		t.Errorf("Expected nil, got %#v", ex)
+	}
+
+	var batch errbatch.ErrBatch
+	batch.Add(errors.New("foo"))
+	batch.Add(errors.New("bar"))
+
+	for _, c := range []struct {
+		label    string
+		err      error
+		expected []string
+	}{
+		{
+			label:    "single",
+			err:      errors.New("foo"),
+			expected: []string{"foo"},
+		},
+		{
+			label:    "batch",
+			err:      batch.Compile(),
+			expected: []string{"foo", "bar"},
+		},
+	} {
+		t.Run(c.label, func(t *testing.T) {
+			ex := thriftbatch.FromError(c.err)
+			if ex.Message != c.err.Error() {
+				t.Errorf("Expected message %q, got %q", c.err.Error(), ex.Message)
+			}
+			if !reflect.DeepEqual(ex.Errors, c.expected) {
+				t.Errorf("Expected %#v, got %#v", c.expected, ex.Errors)
+			}
+		})
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	var batch errbatch.ErrBatch
+	batch.Add(errors.New("foo"))
+	batch.Add(errors.New("bar"))
+	ex := thriftbatch.FromError(batch.Compile())
+
+	ctx := context.Background()
+	for _, c := range []struct {
+		label    string
+		protocol thrift.TProtocolFactory
+	}{
+		{
+			label:    "binary",
+			protocol: thrift.NewTBinaryProtocolFactoryConf(nil),
+		},
+		{
+			label:    "compact",
+			protocol: thrift.NewTCompactProtocolFactoryConf(nil),
+		},
+	} {
+		t.Run(c.label, func(t *testing.T) {
+			ser := thrift.NewTSerializer()
+			ser.Protocol = c.protocol.GetProtocol(ser.Transport)
+			buf, err := ser.Write(ctx, ex)
+			if err != nil {
+				t.Fatalf("Write failed: %v", err)
+			}
+
+			de := thrift.NewTDeserializer()
+			de.Protocol = c.protocol.GetProtocol(de.Transport)
+			var got thriftbatch.Exception
+			if err := de.Read(ctx, &got, buf); err != nil {
+				t.Fatalf("Read failed: %v", err)
+			}
+			if !reflect.DeepEqual(&got, ex) {
+				t.Errorf("Expected %#v, got %#v", ex, &got)
+			}
+
+			var another errbatch.ErrBatch
+			another.Add(&got)
+			if len(another.GetErrors()) != 2 {
+				t.Errorf("Expected 2 errors after adding exception, got %v", another.GetErrors())
+			}
+		})
+	}
+}
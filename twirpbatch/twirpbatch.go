@@ -0,0 +1,58 @@
+// Package twirpbatch converts errbatch batches into Twirp errors.
+package twirpbatch
+
+import (
+	"strconv"
+
+	"github.com/twitchtv/twirp"
+
+	"github.com/fishy/errbatch"
+)
+
+// MetaPrefix is the prefix of the metadata keys used by ToTwirpError.
+//
+// The key of the i-th error in the batch is MetaPrefix followed by i,
+// e.g. "err_0", "err_1".
+const MetaPrefix = "err_"
+
+// ToTwirpError converts err into a twirp.Error with code.
+//
+// The message of the twirp.Error is err's message,
+// and each underlying error of the batch
+// (or err itself if it's not a batch)
+// is attached as metadata, keyed by MetaPrefix and its index.
+// So Twirp clients can get the full details of the batch,
+// instead of a single flattened message.
+//
+// If err is already a twirp.Error, it's returned as-is.
+// It returns nil if err is nil.
+func ToTwirpError(code twirp.ErrorCode, err error) twirp.Error {
+	if err == nil {
+		return nil
+	}
+	if te, ok := err.(twirp.Error); ok {
+		return te
+	}
+
+	var batch errbatch.ErrBatch
+	batch.Add(err)
+	te := twirp.NewError(code, err.Error())
+	for i, err := range batch.GetErrors() {
+		te = te.WithMeta(MetaPrefix+strconv.Itoa(i), err.Error())
+	}
+	return te
+}
+
+// Errors returns the messages attached to te by ToTwirpError, in order.
+//
+// It's useful on the client side to get the individual messages back.
+func Errors(te twirp.Error) []string {
+	var msgs []string
+	for i := 0; ; i++ {
+		msg, ok := te.MetaMap()[MetaPrefix+strconv.Itoa(i)]
+		if !ok {
+			return msgs
+		}
+		msgs = append(msgs, msg)
+	}
+}
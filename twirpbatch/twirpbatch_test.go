@@ -0,0 +1,71 @@
+package twirpbatch_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/twitchtv/twirp"
+
+	"github.com/fishy/errbatch"
+	"github.com/fishy/errbatch/twirpbatch"
+)
+
+func TestToTwirpError(t *testing.T) {
+	if te := twirpbatch.ToTwirpError(twirp.Internal, nil); te != nil {
+		t.Errorf("Expected nil, got %#v", te)
+	}
+
+	var batch errbatch.ErrBatch
+	batch.Add(errors.New("foo"))
+	batch.Add(errors.New("bar"))
+	existing := twirp.NewError(twirp.NotFound, "not found")
+
+	for _, c := range []struct {
+		label    string
+		err      error
+		code     twirp.ErrorCode
+		msg      string
+		expected []string
+	}{
+		{
+			label:    "single",
+			err:      errors.New("foo"),
+			code:     twirp.Internal,
+			msg:      "foo",
+			expected: []string{"foo"},
+		},
+		{
+			label:    "batch",
+			err:      batch.Compile(),
+			code:     twirp.InvalidArgument,
+			msg:      batch.Compile().Error(),
+			expected: []string{"foo", "bar"},
+		},
+		{
+			label: "twirp-error",
+			err:   existing,
+			code:  twirp.NotFound,
+			msg:   "not found",
+		},
+	} {
+		t.Run(c.label, func(t *testing.T) {
+			te := twirpbatch.ToTwirpError(c.code, c.err)
+			if te.Code() != c.code {
+				t.Errorf("Expected code %q, got %q", c.code, te.Code())
+			}
+			if te.Msg() != c.msg {
+				t.Errorf("Expected message %q, got %q", c.msg, te.Msg())
+			}
+			if actual := twirpbatch.Errors(te); !reflect.DeepEqual(actual, c.expected) {
+				t.Errorf("Expected %#v, got %#v", c.expected, actual)
+			}
+		})
+	}
+
+	batch.Add(twirpbatch.ToTwirpError(twirp.Internal, errors.New("foobar")))
+	te := twirpbatch.ToTwirpError(twirp.Internal, batch.Compile())
+	if te.Meta("err_2") != "twirp error internal: foobar" {
+		t.Errorf("Unexpected metadata: %#v", te.MetaMap())
+	}
+}
@@ -0,0 +1,53 @@
+package errbatch
+
+// AddWeighted adds an error with weight into the batch.
+//
+// Errors added without weights (e.g. via Add) have weight 1.
+//
+// If the error is also an ErrBatch,
+// the weight will be attached to each of its underlying error(s).
+//
+// Nil error will be skipped.
+func (eb *ErrBatch) AddWeighted(err error, weight float64) {
+	eb.add(err, func(e *entry) {
+		meta := e.editMeta()
+		meta.weight = weight
+		meta.weighted = true
+	})
+}
+
+// getWeight returns the weight of the entry.
+func (e entry) getWeight() float64 {
+	if meta := e.getMeta(); meta.weighted {
+		return meta.weight
+	}
+	return 1
+}
+
+// Weight returns the cumulative weight of the errors in the batch.
+func (eb *ErrBatch) Weight() float64 {
+	var sum float64
+	for _, e := range eb.entries {
+		sum += e.getWeight()
+	}
+	return sum
+}
+
+// CompileIfWeight compiles the batch the same as Compile,
+// but only when the cumulative weight of the errors in the batch reaches
+// min.
+// Otherwise it returns nil,
+// which is also delivered to the Notifier set by WithNotifier.
+//
+// It's useful for partial-failure policies where some errors matter more
+// than others, for example:
+//
+//	batch.AddWeighted(err, 0.1) // cache miss, tolerable
+//	batch.AddWeighted(err, 1)   // database failure
+//	return batch.CompileIfWeight(1)
+func (eb *ErrBatch) CompileIfWeight(min float64) error {
+	if eb.Weight() < min {
+		return eb.notify(nil)
+	}
+	return eb.Compile()
+}
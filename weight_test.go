@@ -0,0 +1,50 @@
+package errbatch_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/fishy/errbatch"
+)
+
+func TestCompileIfWeight(t *testing.T) {
+	var batch errbatch.ErrBatch
+	if err := batch.CompileIfWeight(0); err != nil {
+		t.Errorf("Expected nil for empty batch, got %#v", err)
+	}
+
+	err0 := errors.New("foo")
+	batch.AddWeighted(err0, 0.25)
+	batch.AddWeighted(nil, 1)
+	if w := batch.Weight(); w != 0.25 {
+		t.Errorf("Expected weight 0.25, got %v", w)
+	}
+	if err := batch.CompileIfWeight(1); err != nil {
+		t.Errorf("Expected nil under threshold, got %#v", err)
+	}
+	if err := batch.CompileIfWeight(0.25); err != err0 {
+		t.Errorf("Expected %#v at threshold, got %#v", err0, err)
+	}
+
+	var another errbatch.ErrBatch
+	another.Add(errors.New("bar"))
+	another.Add(errors.New("foobar"))
+	batch.AddWeighted(&another, 0.25)
+	if w := batch.Weight(); w != 0.75 {
+		t.Errorf("Expected weight 0.75, got %v", w)
+	}
+
+	batch.Add(errors.New("unweighted"))
+	if w := batch.Weight(); w != 1.75 {
+		t.Errorf("Expected weight 1.75, got %v", w)
+	}
+	if err := batch.CompileIfWeight(1); err == nil {
+		t.Error("Expected non-nil error over threshold")
+	}
+
+	var merged errbatch.ErrBatch
+	merged.Add(&batch)
+	if w := merged.Weight(); w != 1.75 {
+		t.Errorf("Expected weights to be kept when flattened, got %v", w)
+	}
+}
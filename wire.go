@@ -0,0 +1,198 @@
+package errbatch
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// wireFormat abstracts the array and string framing of the serialization
+// formats sharing the same layout (binary, CBOR and msgpack),
+// which is:
+//
+//	[traceparent, [[message, type name, trace ID, [stack frames...]]...]]
+type wireFormat interface {
+	appendArray(buf []byte, n int) []byte
+	appendString(buf []byte, s string) []byte
+	readArray(d *wireDecoder) int
+	readString(d *wireDecoder) string
+}
+
+// wireEntryFields is the number of fields of each entry in the wire layout.
+const wireEntryFields = 4
+
+// WithLosslessEncoding makes MarshalBinary, MarshalCBOR and MarshalMsgpack
+// encode the messages of the underlying errors without redacting them by
+// WithRedactor,
+// so the batch can be restored losslessly.
+//
+// As the redacted parts (e.g. secrets) are kept,
+// only use it when the encoded batch does not leave the trust boundary.
+func WithLosslessEncoding() Option {
+	return func(o *options) {
+		o.lossless = true
+	}
+}
+
+// appendWire appends the batch encoded in format to buf.
+//
+// The messages are redacted by WithRedactor,
+// unless the batch is created with WithLosslessEncoding.
+func (eb *ErrBatch) appendWire(buf []byte, format wireFormat) []byte {
+	opts := eb.getOptions()
+	buf = format.appendArray(buf, 2)
+	buf = format.appendString(buf, eb.traceparent)
+	buf = format.appendArray(buf, len(eb.entries))
+	for _, e := range eb.entries {
+		msg := errorMessage(e.err)
+		if !opts.lossless {
+			msg = opts.redact(msg)
+		}
+		buf = format.appendArray(buf, wireEntryFields)
+		buf = format.appendString(buf, msg)
+		buf = format.appendString(buf, typeName(e.err))
+		buf = format.appendString(buf, e.getMeta().traceID)
+		stack := e.stackStrings()
+		buf = format.appendArray(buf, len(stack))
+		for _, frame := range stack {
+			buf = format.appendString(buf, frame)
+		}
+	}
+	return buf
+}
+
+// decodeWire replaces the errors and traceparent of the batch with the ones
+// decoded from data in format, as *DecodedError.
+//
+// The decoded errors are added the same as Add,
+// so the options of the batch (e.g. WithIgnore, WithDedup and the retention
+// policies) apply to them.
+// On errors the batch is not changed.
+func (eb *ErrBatch) decodeWire(data []byte, format wireFormat) error {
+	opts := eb.getOptions()
+	site := callSite{
+		sequence:  opts.sequenceOrder,
+		timestamp: opts.timestamps,
+	}
+	d := wireDecoder{data: data}
+	d.expectArray(format, 2)
+	traceparent := format.readString(&d)
+	entries := make([]entry, 0, format.readArray(&d))
+	for i := cap(entries); i > 0 && d.err == nil; i-- {
+		d.expectArray(format, wireEntryFields)
+		decoded := &DecodedError{
+			Message:  format.readString(&d),
+			TypeName: format.readString(&d),
+		}
+		traceID := format.readString(&d)
+		if n := format.readArray(&d); n > 0 {
+			decoded.Stack = make([]string, n)
+			for j := range decoded.Stack {
+				decoded.Stack[j] = format.readString(&d)
+			}
+		}
+		e := site.newEntry(decoded)
+		if traceID != "" {
+			e.editMeta().traceID = traceID
+		}
+		entries = append(entries, e)
+	}
+	if d.err == nil && len(d.data) > 0 {
+		d.err = fmt.Errorf("%d trailing bytes", len(d.data))
+	}
+	if d.err != nil {
+		return d.err
+	}
+	eb.Clear()
+	eb.traceparent = traceparent
+	entries, eb.skipped = opts.filter(entries)
+	countAdded(eb.appendEntries(entries...))
+	return nil
+}
+
+// wireDecoder reads the data of a wireFormat.
+//
+// After the first error, all reads return zero values and err is kept.
+type wireDecoder struct {
+	data []byte
+	err  error
+}
+
+func (d *wireDecoder) fail(err error) {
+	if d.err == nil {
+		d.err = err
+	}
+}
+
+// next consumes and returns the next n bytes.
+func (d *wireDecoder) next(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if n > len(d.data) {
+		d.fail(fmt.Errorf("need %d bytes, only %d remaining", n, len(d.data)))
+		return nil
+	}
+	b := d.data[:n]
+	d.data = d.data[n:]
+	return b
+}
+
+// byte consumes and returns the next byte.
+func (d *wireDecoder) byte() byte {
+	if b := d.next(1); len(b) > 0 {
+		return b[0]
+	}
+	return 0
+}
+
+// uint consumes and returns the next big-endian unsigned integer of size
+// bytes (1, 2, 4 or 8).
+func (d *wireDecoder) uint(size int) uint64 {
+	b := d.next(size)
+	switch len(b) {
+	case 1:
+		return uint64(b[0])
+	case 2:
+		return uint64(binary.BigEndian.Uint16(b))
+	case 4:
+		return uint64(binary.BigEndian.Uint32(b))
+	case 8:
+		return binary.BigEndian.Uint64(b)
+	}
+	return 0
+}
+
+func (d *wireDecoder) uvarint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(d.data)
+	if n <= 0 {
+		d.fail(errors.New("invalid length"))
+		return 0
+	}
+	d.data = d.data[n:]
+	return v
+}
+
+// count checks n as a count of bytes or items following it,
+// which must be bounded by the remaining bytes as each item takes at least
+// one byte.
+func (d *wireDecoder) count(n uint64) int {
+	if d.err != nil {
+		return 0
+	}
+	if n > uint64(len(d.data)) {
+		d.fail(fmt.Errorf("count %d exceeds remaining %d bytes", n, len(d.data)))
+		return 0
+	}
+	return int(n)
+}
+
+// expectArray reads an array header of format and checks it has n items.
+func (d *wireDecoder) expectArray(format wireFormat, n int) {
+	if got := format.readArray(d); d.err == nil && got != n {
+		d.fail(fmt.Errorf("expected array of %d items, got %d", n, got))
+	}
+}
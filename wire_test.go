@@ -0,0 +1,87 @@
+package errbatch_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/fishy/errbatch"
+)
+
+func TestMarshalCBORMsgpack(t *testing.T) {
+	var batch errbatch.ErrBatch
+	batch.Add(errors.New("foo"))
+
+	for _, c := range []struct {
+		label     string
+		marshal   func(*errbatch.ErrBatch) ([]byte, error)
+		unmarshal func(*errbatch.ErrBatch, []byte) error
+		expected  string
+	}{
+		{
+			label:     "cbor",
+			marshal:   (*errbatch.ErrBatch).MarshalCBOR,
+			unmarshal: (*errbatch.ErrBatch).UnmarshalCBOR,
+			expected:  "\x82\x60\x81\x84\x63foo\x73*errors.errorString\x60\x80",
+		},
+		{
+			label:     "msgpack",
+			marshal:   (*errbatch.ErrBatch).MarshalMsgpack,
+			unmarshal: (*errbatch.ErrBatch).UnmarshalMsgpack,
+			expected:  "\x92\xa0\x91\x94\xa3foo\xb3*errors.errorString\xa0\x90",
+		},
+	} {
+		t.Run(c.label, func(t *testing.T) {
+			data, err := c.marshal(&batch)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != c.expected {
+				t.Errorf("Expected %q, got %q", c.expected, data)
+			}
+
+			t.Run("round-trip", func(t *testing.T) {
+				src := errbatch.New(errbatch.WithStack())
+				src.Add(errors.New("foo"))
+				src.AddCoded("code", customError{})
+				if err := src.SetTraceparent(traceparent); err != nil {
+					t.Fatal(err)
+				}
+				data, err := c.marshal(src)
+				if err != nil {
+					t.Fatal(err)
+				}
+				var decoded errbatch.ErrBatch
+				if err := c.unmarshal(&decoded, data); err != nil {
+					t.Fatal(err)
+				}
+				if decoded.Error() != src.Error() {
+					t.Errorf("Expected %q, got %q", src.Error(), decoded.Error())
+				}
+				if decoded.Traceparent() != traceparent {
+					t.Errorf("Expected traceparent %q, got %q", traceparent, decoded.Traceparent())
+				}
+				again, err := c.marshal(&decoded)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(again) != string(data) {
+					t.Errorf("Expected %q, got %q", data, again)
+				}
+			})
+
+			t.Run("malformed", func(t *testing.T) {
+				for _, data := range []string{
+					"",
+					c.expected[:len(c.expected)-1],
+					c.expected + "\x00",
+					"\xff",
+				} {
+					var target errbatch.ErrBatch
+					if err := c.unmarshal(&target, []byte(data)); err == nil {
+						t.Errorf("Expected error for %q, got nil", data)
+					}
+				}
+			})
+		})
+	}
+}
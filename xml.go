@@ -0,0 +1,51 @@
+package errbatch
+
+import (
+	"encoding/xml"
+)
+
+// Make sure *ErrBatch satisfies xml.Marshaler interface.
+var _ xml.Marshaler = (*ErrBatch)(nil)
+
+type xmlBatch struct {
+	XMLName     xml.Name   `xml:"errors"`
+	Count       int        `xml:"count,attr"`
+	Traceparent string     `xml:"traceparent,attr,omitempty"`
+	Errors      []xmlEntry `xml:"error"`
+}
+
+type xmlEntry struct {
+	Type    string `xml:"type,attr"`
+	TraceID string `xml:"trace_id,attr,omitempty"`
+	Message string `xml:",chardata"`
+}
+
+// MarshalXML implements xml.Marshaler.
+//
+// The batch is always encoded as an <errors> element,
+// with the number of errors ("count" attribute),
+// the traceparent set by SetTraceparent (if any),
+// and an <error> element of each of the underlying errors,
+// with its message as the text and its type name and trace ID (if any) as the
+// attributes.
+// The messages are redacted by WithRedactor.
+//
+// For example:
+//
+//	<errors count="2"><error type="*errors.errorString">foo</error><error type="*errors.errorString">bar</error></errors>
+func (eb *ErrBatch) MarshalXML(e *xml.Encoder, _ xml.StartElement) error {
+	opts := eb.getOptions()
+	xb := xmlBatch{
+		Count:       len(eb.entries),
+		Traceparent: eb.traceparent,
+		Errors:      make([]xmlEntry, len(eb.entries)),
+	}
+	for i, entry := range eb.entries {
+		xb.Errors[i] = xmlEntry{
+			Type:    typeName(entry.err),
+			TraceID: entry.getMeta().traceID,
+			Message: opts.redact(errorMessage(entry.err)),
+		}
+	}
+	return e.Encode(xb)
+}
@@ -0,0 +1,58 @@
+package errbatch_test
+
+import (
+	"encoding/xml"
+	"errors"
+	"testing"
+
+	"github.com/fishy/errbatch"
+)
+
+func TestMarshalXML(t *testing.T) {
+	var batch errbatch.ErrBatch
+	batch.Add(errors.New("foo <bar>"))
+	batch.AddCoded("code", customError{})
+
+	buf, err := xml.Marshal(&batch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const expected = `<errors count="2">` +
+		`<error type="*errors.errorString">foo &lt;bar&gt;</error>` +
+		`<error type="errbatch_test.customError">custom</error>` +
+		`</errors>`
+	if string(buf) != expected {
+		t.Errorf("Expected %s, got %s", expected, buf)
+	}
+
+	t.Run("traceparent", func(t *testing.T) {
+		if err := batch.SetTraceparent(traceparent); err != nil {
+			t.Fatal(err)
+		}
+		buf, err := xml.Marshal(&batch)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var decoded struct {
+			Traceparent string `xml:"traceparent,attr"`
+		}
+		if err := xml.Unmarshal(buf, &decoded); err != nil {
+			t.Fatal(err)
+		}
+		if decoded.Traceparent != traceparent {
+			t.Errorf("Expected %q, got %q", traceparent, decoded.Traceparent)
+		}
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		var batch errbatch.ErrBatch
+		buf, err := xml.Marshal(&batch)
+		if err != nil {
+			t.Fatal(err)
+		}
+		const expected = `<errors count="0"></errors>`
+		if string(buf) != expected {
+			t.Errorf("Expected %s, got %s", expected, buf)
+		}
+	})
+}
@@ -0,0 +1,26 @@
+package errbatch
+
+// YAMLEntry is the YAML representation of each of the underlying errors of a
+// batch, as produced by (*ErrBatch).MarshalYAML.
+type YAMLEntry struct {
+	Message string `yaml:"message"`
+	Type    string `yaml:"type"`
+}
+
+// MarshalYAML implements the Marshaler interfaces of both gopkg.in/yaml.v2 and
+// gopkg.in/yaml.v3, without depending on either of them.
+//
+// The batch is encoded as a list of the message and type name of each of the
+// underlying errors.
+// The messages are redacted by WithRedactor.
+func (eb *ErrBatch) MarshalYAML() (interface{}, error) {
+	opts := eb.getOptions()
+	entries := make([]YAMLEntry, len(eb.entries))
+	for i, e := range eb.entries {
+		entries[i] = YAMLEntry{
+			Message: opts.redact(errorMessage(e.err)),
+			Type:    typeName(e.err),
+		}
+	}
+	return entries, nil
+}
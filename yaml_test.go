@@ -0,0 +1,45 @@
+package errbatch_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/fishy/errbatch"
+)
+
+func TestMarshalYAML(t *testing.T) {
+	var batch errbatch.ErrBatch
+	batch.Add(errors.New("foo"))
+	batch.AddCoded("code", customError{})
+
+	v, err := batch.MarshalYAML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []errbatch.YAMLEntry{
+		{Message: "foo", Type: "*errors.errorString"},
+		{Message: "custom", Type: "errbatch_test.customError"},
+	}
+	if !reflect.DeepEqual(v, expected) {
+		t.Errorf("Expected %#v, got %#v", expected, v)
+	}
+}
+
+func TestMarshalYAMLRedactor(t *testing.T) {
+	batch := errbatch.New(errbatch.WithRedactor(func(string) string {
+		return "redacted"
+	}))
+	batch.Add(errors.New("secret"))
+
+	v, err := batch.MarshalYAML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []errbatch.YAMLEntry{
+		{Message: "redacted", Type: "*errors.errorString"},
+	}
+	if !reflect.DeepEqual(v, expected) {
+		t.Errorf("Expected %#v, got %#v", expected, v)
+	}
+}
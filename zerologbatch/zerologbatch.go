@@ -0,0 +1,82 @@
+// Package zerologbatch provides adapters to log errbatch.ErrBatch with zerolog
+// in a structured way.
+package zerologbatch
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/rs/zerolog"
+
+	"github.com/fishy/errbatch"
+)
+
+// Make sure the adapters satisfy zerolog interfaces.
+var (
+	_ zerolog.LogArrayMarshaler  = entries(nil)
+	_ zerolog.LogObjectMarshaler = object{}
+)
+
+// Keys used by the adapters.
+const (
+	CountKey   = "count"
+	ErrorsKey  = "errors"
+	MessageKey = "message"
+	TypeKey    = "type"
+)
+
+// Array returns a zerolog.LogArrayMarshaler that encodes err as a JSON array,
+// with one object (message and type) per underlying error.
+//
+// If err is an ErrBatch (or wraps one),
+// the underlying errors will be encoded individually.
+// Otherwise err is treated as a batch containing only itself.
+// Nil err encodes to an empty array.
+//
+// Example:
+//
+//	log.Error().Array("errors", zerologbatch.Array(err)).Msg("workers failed")
+func Array(err error) zerolog.LogArrayMarshaler {
+	return getEntries(err)
+}
+
+// Object returns a zerolog.LogObjectMarshaler that encodes err as a JSON
+// object, with the count of the underlying errors and the same array as Array.
+//
+// Example:
+//
+//	log.Error().Object("errors", zerologbatch.Object(err)).Msg("workers failed")
+func Object(err error) zerolog.LogObjectMarshaler {
+	return object{entries: getEntries(err)}
+}
+
+func getEntries(err error) entries {
+	var batch *errbatch.ErrBatch
+	if errors.As(err, &batch) {
+		return batch.GetErrors()
+	}
+	if err != nil {
+		return entries{err}
+	}
+	return entries{}
+}
+
+type entries []error
+
+func (e entries) MarshalZerologArray(a *zerolog.Array) {
+	for _, err := range e {
+		a.Dict(zerolog.Dict().
+			Str(MessageKey, err.Error()).
+			Str(TypeKey, fmt.Sprintf("%T", err)),
+		)
+	}
+}
+
+type object struct {
+	entries entries
+}
+
+func (o object) MarshalZerologObject(e *zerolog.Event) {
+	e.Int(CountKey, len(o.entries))
+	e.Array(ErrorsKey, o.entries)
+}
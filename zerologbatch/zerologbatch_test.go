@@ -0,0 +1,72 @@
+package zerologbatch_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+
+	"github.com/fishy/errbatch"
+	"github.com/fishy/errbatch/zerologbatch"
+)
+
+func TestArray(t *testing.T) {
+	var batch errbatch.ErrBatch
+	batch.Add(errors.New("foo"))
+	batch.Add(errors.New("bar"))
+
+	for _, c := range []struct {
+		label    string
+		err      error
+		expected string
+	}{
+		{
+			label:    "nil",
+			err:      nil,
+			expected: `{"errors":[]}`,
+		},
+		{
+			label:    "single",
+			err:      errors.New("foo"),
+			expected: `{"errors":[{"message":"foo","type":"*errors.errorString"}]}`,
+		},
+		{
+			label: "batch",
+			err:   batch.Compile(),
+			expected: `{"errors":[` +
+				`{"message":"foo","type":"*errors.errorString"},` +
+				`{"message":"bar","type":"*errors.errorString"}` +
+				`]}`,
+		},
+	} {
+		t.Run(c.label, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger := zerolog.New(&buf)
+			logger.Log().Array("errors", zerologbatch.Array(c.err)).Send()
+			actual := strings.TrimSpace(buf.String())
+			if actual != c.expected {
+				t.Errorf("Expected %s, got %s", c.expected, actual)
+			}
+		})
+	}
+}
+
+func TestObject(t *testing.T) {
+	var batch errbatch.ErrBatch
+	batch.Add(errors.New("foo"))
+	batch.Add(errors.New("bar"))
+
+	var buf bytes.Buffer
+	logger := zerolog.New(&buf)
+	logger.Log().Object("errors", zerologbatch.Object(batch.Compile())).Send()
+	expected := `{"errors":{"count":2,"errors":[` +
+		`{"message":"foo","type":"*errors.errorString"},` +
+		`{"message":"bar","type":"*errors.errorString"}` +
+		`]}}`
+	actual := strings.TrimSpace(buf.String())
+	if actual != expected {
+		t.Errorf("Expected %s, got %s", expected, actual)
+	}
+}